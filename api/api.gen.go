@@ -0,0 +1,1838 @@
+// Package api provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.4.1 DO NOT EDIT.
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/oapi-codegen/runtime"
+)
+
+const (
+	ApiKeyAuthScopes = "apiKeyAuth.Scopes"
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for BannerRevisionStatus.
+const (
+	Approved  BannerRevisionStatus = "approved"
+	Archived  BannerRevisionStatus = "archived"
+	Draft     BannerRevisionStatus = "draft"
+	InReview  BannerRevisionStatus = "in_review"
+	Published BannerRevisionStatus = "published"
+)
+
+// Defines values for ErrorResponseCode.
+const (
+	BadRequest           ErrorResponseCode = "bad_request"
+	Conflict             ErrorResponseCode = "conflict"
+	Forbidden            ErrorResponseCode = "forbidden"
+	IdempotencyKeyReused ErrorResponseCode = "idempotency_key_reused"
+	InternalError        ErrorResponseCode = "internal_error"
+	MethodNotAllowed     ErrorResponseCode = "method_not_allowed"
+	NotFound             ErrorResponseCode = "not_found"
+	RateLimited          ErrorResponseCode = "rate_limited"
+	Unauthorized         ErrorResponseCode = "unauthorized"
+	Unavailable          ErrorResponseCode = "unavailable"
+	ValidationFailed     ErrorResponseCode = "validation_failed"
+)
+
+// Defines values for HealthCheckStatus.
+const (
+	HealthCheckStatusDegraded HealthCheckStatus = "degraded"
+	HealthCheckStatusFail     HealthCheckStatus = "fail"
+	HealthCheckStatusOk       HealthCheckStatus = "ok"
+)
+
+// Defines values for HealthResponseStatus.
+const (
+	HealthResponseStatusDegraded HealthResponseStatus = "degraded"
+	HealthResponseStatusFail     HealthResponseStatus = "fail"
+	HealthResponseStatusOk       HealthResponseStatus = "ok"
+)
+
+// Defines values for AuditGetParamsAction.
+const (
+	ApiKeyCreate AuditGetParamsAction = "api_key_create"
+	ApiKeyRevoke AuditGetParamsAction = "api_key_revoke"
+	ApiKeyRotate AuditGetParamsAction = "api_key_rotate"
+	Create       AuditGetParamsAction = "create"
+	Delete       AuditGetParamsAction = "delete"
+	Import       AuditGetParamsAction = "import"
+	Transition   AuditGetParamsAction = "transition"
+	Update       AuditGetParamsAction = "update"
+)
+
+// Defines values for BannerExportParamsFormat.
+const (
+	BannerExportParamsFormatCsv    BannerExportParamsFormat = "csv"
+	BannerExportParamsFormatNdjson BannerExportParamsFormat = "ndjson"
+)
+
+// Defines values for BannerImportParamsFormat.
+const (
+	BannerImportParamsFormatCsv    BannerImportParamsFormat = "csv"
+	BannerImportParamsFormatNdjson BannerImportParamsFormat = "ndjson"
+)
+
+// Defines values for BannerTransitionPostJSONBodyAction.
+const (
+	Approve  BannerTransitionPostJSONBodyAction = "approve"
+	Publish  BannerTransitionPostJSONBodyAction = "publish"
+	Reject   BannerTransitionPostJSONBodyAction = "reject"
+	Submit   BannerTransitionPostJSONBodyAction = "submit"
+	Withdraw BannerTransitionPostJSONBodyAction = "withdraw"
+)
+
+// APIKey defines model for APIKey.
+type APIKey struct {
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	Id         *int       `json:"id,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Name       *string    `json:"name,omitempty"`
+
+	// Prefix Начало ключа, чтобы узнать его в списке
+	Prefix    *string    `json:"prefix,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Role      *string    `json:"role,omitempty"`
+	Scopes    *[]string  `json:"scopes,omitempty"`
+
+	// Tenant Тенант, к которому ключ дает доступ
+	Tenant *string `json:"tenant,omitempty"`
+}
+
+// APIKeyCreated defines model for APIKeyCreated.
+type APIKeyCreated struct {
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	Id         *int       `json:"id,omitempty"`
+	Key        *string    `json:"key,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Name       *string    `json:"name,omitempty"`
+
+	// Prefix Начало ключа, чтобы узнать его в списке
+	Prefix    *string    `json:"prefix,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Role      *string    `json:"role,omitempty"`
+	Scopes    *[]string  `json:"scopes,omitempty"`
+
+	// Tenant Тенант, к которому ключ дает доступ
+	Tenant *string `json:"tenant,omitempty"`
+}
+
+// BannerRevision defines model for BannerRevision.
+type BannerRevision struct {
+	BannerId  *int                    `json:"banner_id,omitempty"`
+	Content   *map[string]interface{} `json:"content,omitempty"`
+	CreatedAt *time.Time              `json:"created_at,omitempty"`
+	CreatedBy *string                 `json:"created_by,omitempty"`
+	FeatureId *int                    `json:"feature_id,omitempty"`
+	IsActive  *bool                   `json:"is_active,omitempty"`
+
+	// Revision Номер ревизии баннера
+	Revision   *int                  `json:"revision,omitempty"`
+	RevisionId *int                  `json:"revision_id,omitempty"`
+	Status     *BannerRevisionStatus `json:"status,omitempty"`
+	TagIds     *[]int                `json:"tag_ids,omitempty"`
+	UpdatedAt  *time.Time            `json:"updated_at,omitempty"`
+}
+
+// BannerRevisionStatus defines model for BannerRevision.Status.
+type BannerRevisionStatus string
+
+// ErrorResponse Тело любого ответа с ошибкой. Клиенты различают ошибки по code, текст error предназначен для людей и может меняться
+type ErrorResponse struct {
+	// Code Стабильный код ошибки: bad_request - неверные параметры или тело запроса, validation_failed - запрос не соответствует спецификации или content не прошел проверку, подробности в fields, idempotency_key_reused - Idempotency-Key уже использован с другим телом, unavailable - база данных или кэш временно недоступны
+	Code ErrorResponseCode `json:"code"`
+
+	// Error Описание ошибки
+	Error string `json:"error"`
+
+	// Fields Ошибки по полям, например при несоответствии content схеме фичи
+	Fields *[]FieldError `json:"fields,omitempty"`
+
+	// RequestId Идентификатор запроса, совпадает с заголовком X-Request-ID
+	RequestId *string `json:"request_id,omitempty"`
+}
+
+// ErrorResponseCode Стабильный код ошибки: bad_request - неверные параметры или тело запроса, validation_failed - запрос не соответствует спецификации или content не прошел проверку, подробности в fields, idempotency_key_reused - Idempotency-Key уже использован с другим телом, unavailable - база данных или кэш временно недоступны
+type ErrorResponseCode string
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	Error *string `json:"error,omitempty"`
+
+	// Field JSON Pointer на поле с ошибкой, для импорта - номер строки (row 3)
+	Field *string `json:"field,omitempty"`
+}
+
+// HealthCheck defines model for HealthCheck.
+type HealthCheck struct {
+	Error *string `json:"error,omitempty"`
+
+	// LatencyMs Время проверки в миллисекундах
+	LatencyMs *float32           `json:"latency_ms,omitempty"`
+	Status    *HealthCheckStatus `json:"status,omitempty"`
+}
+
+// HealthCheckStatus defines model for HealthCheck.Status.
+type HealthCheckStatus string
+
+// HealthResponse defines model for HealthResponse.
+type HealthResponse struct {
+	// Checks Проверки database, migrations и cache
+	Checks *map[string]HealthCheck `json:"checks,omitempty"`
+	Status *HealthResponseStatus   `json:"status,omitempty"`
+}
+
+// HealthResponseStatus defines model for HealthResponse.Status.
+type HealthResponseStatus string
+
+// ImportResult defines model for ImportResult.
+type ImportResult struct {
+	BannerIds *[]int `json:"banner_ids,omitempty"`
+	DryRun    *bool  `json:"dry_run,omitempty"`
+
+	// Imported Количество созданных баннеров
+	Imported *int `json:"imported,omitempty"`
+
+	// Total Количество строк во входных данных
+	Total *int `json:"total,omitempty"`
+}
+
+// TooManyRequests Тело любого ответа с ошибкой. Клиенты различают ошибки по code, текст error предназначен для людей и может меняться
+type TooManyRequests = ErrorResponse
+
+// APIKeyPostJSONBody defines parameters for APIKeyPost.
+type APIKeyPostJSONBody struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Name      string     `json:"name"`
+
+	// Role Встроенная роль (user, admin, editor, approver) или роль из RBAC_ROLES_FILE
+	Role string `json:"role"`
+
+	// Scopes Дополнительные права, например banner:write или banner:write@1,2
+	Scopes *[]string `json:"scopes,omitempty"`
+}
+
+// AuditGetParams defines parameters for AuditGet.
+type AuditGetParams struct {
+	Actor    *string               `form:"actor,omitempty" json:"actor,omitempty"`
+	BannerId *int                  `form:"banner_id,omitempty" json:"banner_id,omitempty"`
+	ApiKeyId *int                  `form:"api_key_id,omitempty" json:"api_key_id,omitempty"`
+	Action   *AuditGetParamsAction `form:"action,omitempty" json:"action,omitempty"`
+	From     *time.Time            `form:"from,omitempty" json:"from,omitempty"`
+	To       *time.Time            `form:"to,omitempty" json:"to,omitempty"`
+	Limit    *int                  `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset   *int                  `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Token Токен админа
+	Token *string `json:"token,omitempty"`
+}
+
+// AuditGetParamsAction defines parameters for AuditGet.
+type AuditGetParamsAction string
+
+// BannerGetParams defines parameters for BannerGet.
+type BannerGetParams struct {
+	FeatureId *int `form:"feature_id,omitempty" json:"feature_id,omitempty"`
+	TagId     *int `form:"tag_id,omitempty" json:"tag_id,omitempty"`
+	Limit     *int `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset    *int `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Token Токен админа
+	Token *string `json:"token,omitempty"`
+}
+
+// BannerPostJSONBody defines parameters for BannerPost.
+type BannerPostJSONBody struct {
+	// Content Содержимое баннера. Локализованный content содержит единственный ключ locales с вариантами по локалям, например {"locales": {"en": {...}, "ru": {...}}}. Строки могут содержать шаблоны {{name}} и {{name|значение по умолчанию}}, допустимы только переменные из TEMPLATE_VARS
+	Content map[string]interface{} `json:"content"`
+
+	// FeatureId Идентификатор фичи
+	FeatureId int `json:"feature_id"`
+
+	// IsActive Флаг активности баннера
+	IsActive *bool `json:"is_active,omitempty"`
+
+	// TagIds Идентификаторы тэгов
+	TagIds []int `json:"tag_ids"`
+}
+
+// BannerPostParams defines parameters for BannerPost.
+type BannerPostParams struct {
+	// Token Токен админа
+	Token *string `json:"token,omitempty"`
+
+	// IdempotencyKey Ключ идемпотентности. Повторный запрос с тем же ключом и телом вернет сохраненный ответ
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
+}
+
+// BannerExportParams defines parameters for BannerExport.
+type BannerExportParams struct {
+	Format    *BannerExportParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+	FeatureId *int                      `form:"feature_id,omitempty" json:"feature_id,omitempty"`
+	TagId     *int                      `form:"tag_id,omitempty" json:"tag_id,omitempty"`
+
+	// Token Токен админа
+	Token *string `json:"token,omitempty"`
+}
+
+// BannerExportParamsFormat defines parameters for BannerExport.
+type BannerExportParamsFormat string
+
+// BannerImportParams defines parameters for BannerImport.
+type BannerImportParams struct {
+	Format *BannerImportParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+	DryRun *bool                     `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+
+	// Token Токен редактора или админа
+	Token *string `json:"token,omitempty"`
+}
+
+// BannerImportParamsFormat defines parameters for BannerImport.
+type BannerImportParamsFormat string
+
+// BannerIdDeleteParams defines parameters for BannerIdDelete.
+type BannerIdDeleteParams struct {
+	// Token Токен админа
+	Token *string `json:"token,omitempty"`
+}
+
+// BannerIdPatchJSONBody defines parameters for BannerIdPatch.
+type BannerIdPatchJSONBody struct {
+	// Content Содержимое баннера
+	Content *map[string]interface{} `json:"content"`
+
+	// FeatureId Идентификатор фичи
+	FeatureId *int `json:"feature_id"`
+
+	// IsActive Флаг активности баннера
+	IsActive *bool `json:"is_active"`
+
+	// TagIds Идентификаторы тэгов
+	TagIds *[]int `json:"tag_ids"`
+}
+
+// BannerIdPatchParams defines parameters for BannerIdPatch.
+type BannerIdPatchParams struct {
+	// Token Токен редактора или админа
+	Token *string `json:"token,omitempty"`
+}
+
+// BannerHistoryGetParams defines parameters for BannerHistoryGet.
+type BannerHistoryGetParams struct {
+	// Token Токен редактора, согласующего или админа
+	Token *string `json:"token,omitempty"`
+}
+
+// BannerPreviewPostJSONBody defines parameters for BannerPreviewPost.
+type BannerPreviewPostJSONBody struct {
+	// Ttl Время жизни ссылки, по умолчанию 1h, не больше PREVIEW_MAX_TTL
+	Ttl *string `json:"ttl,omitempty"`
+}
+
+// BannerPreviewPostParams defines parameters for BannerPreviewPost.
+type BannerPreviewPostParams struct {
+	// Token Токен редактора, согласующего или админа
+	Token *string `json:"token,omitempty"`
+}
+
+// BannerTransitionPostJSONBody defines parameters for BannerTransitionPost.
+type BannerTransitionPostJSONBody struct {
+	Action  BannerTransitionPostJSONBodyAction `json:"action"`
+	Comment *string                            `json:"comment,omitempty"`
+}
+
+// BannerTransitionPostParams defines parameters for BannerTransitionPost.
+type BannerTransitionPostParams struct {
+	// Token Токен редактора, согласующего или админа
+	Token *string `json:"token,omitempty"`
+}
+
+// BannerTransitionPostJSONBodyAction defines parameters for BannerTransitionPost.
+type BannerTransitionPostJSONBodyAction string
+
+// FeatureSchemaDeleteParams defines parameters for FeatureSchemaDelete.
+type FeatureSchemaDeleteParams struct {
+	// Token Токен админа
+	Token *string `json:"token,omitempty"`
+}
+
+// FeatureSchemaGetParams defines parameters for FeatureSchemaGet.
+type FeatureSchemaGetParams struct {
+	// Token Токен админа
+	Token *string `json:"token,omitempty"`
+}
+
+// FeatureSchemaPutJSONBody defines parameters for FeatureSchemaPut.
+type FeatureSchemaPutJSONBody map[string]interface{}
+
+// FeatureSchemaPutParams defines parameters for FeatureSchemaPut.
+type FeatureSchemaPutParams struct {
+	// Token Токен админа
+	Token *string `json:"token,omitempty"`
+}
+
+// FeatureSchemaValidateParams defines parameters for FeatureSchemaValidate.
+type FeatureSchemaValidateParams struct {
+	// Token Токен админа
+	Token *string `json:"token,omitempty"`
+}
+
+// UserBannerGetParams defines parameters for UserBannerGet.
+type UserBannerGetParams struct {
+	// TagId Обязателен без preview_token
+	TagId *int `form:"tag_id,omitempty" json:"tag_id,omitempty"`
+
+	// FeatureId Обязателен без preview_token
+	FeatureId       *int    `form:"feature_id,omitempty" json:"feature_id,omitempty"`
+	UseLastRevision *bool   `form:"use_last_revision,omitempty" json:"use_last_revision,omitempty"`
+	PreviewToken    *string `form:"preview_token,omitempty" json:"preview_token,omitempty"`
+	Locale          *string `form:"locale,omitempty" json:"locale,omitempty"`
+
+	// TemplateVars Значения переменных шаблонов content. Каждая переменная из TEMPLATE_VARS передается отдельным параметром, например ?name=Anna&city=Kazan
+	TemplateVars   *map[string]string `form:"template_vars,omitempty" json:"template_vars,omitempty"`
+	AcceptLanguage *string            `json:"Accept-Language,omitempty"`
+
+	// Token Токен пользователя
+	Token *string `json:"token,omitempty"`
+}
+
+// APIKeyPostJSONRequestBody defines body for APIKeyPost for application/json ContentType.
+type APIKeyPostJSONRequestBody APIKeyPostJSONBody
+
+// BannerPostJSONRequestBody defines body for BannerPost for application/json ContentType.
+type BannerPostJSONRequestBody BannerPostJSONBody
+
+// BannerIdPatchJSONRequestBody defines body for BannerIdPatch for application/json ContentType.
+type BannerIdPatchJSONRequestBody BannerIdPatchJSONBody
+
+// BannerPreviewPostJSONRequestBody defines body for BannerPreviewPost for application/json ContentType.
+type BannerPreviewPostJSONRequestBody BannerPreviewPostJSONBody
+
+// BannerTransitionPostJSONRequestBody defines body for BannerTransitionPost for application/json ContentType.
+type BannerTransitionPostJSONRequestBody BannerTransitionPostJSONBody
+
+// FeatureSchemaPutJSONRequestBody defines body for FeatureSchemaPut for application/json ContentType.
+type FeatureSchemaPutJSONRequestBody FeatureSchemaPutJSONBody
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Список API ключей без самих ключей
+	// (GET /api_keys)
+	APIKeysGet(w http.ResponseWriter, r *http.Request)
+	// Создание API ключа для сервиса
+	// (POST /api_keys)
+	APIKeyPost(w http.ResponseWriter, r *http.Request)
+	// Отзыв API ключа
+	// (DELETE /api_keys/{id})
+	APIKeyDelete(w http.ResponseWriter, r *http.Request, id int)
+	// Перевыпуск API ключа, старый ключ сразу перестает действовать
+	// (POST /api_keys/{id}/rotate)
+	APIKeyRotatePost(w http.ResponseWriter, r *http.Request, id int)
+	// Журнал изменений баннеров и API ключей
+	// (GET /audit)
+	AuditGet(w http.ResponseWriter, r *http.Request, params AuditGetParams)
+	// Получение всех баннеров c фильтрацией по фиче и/или тегу
+	// (GET /banner)
+	BannerGet(w http.ResponseWriter, r *http.Request, params BannerGetParams)
+	// Создание нового баннера
+	// (POST /banner)
+	BannerPost(w http.ResponseWriter, r *http.Request, params BannerPostParams)
+	// Выгрузка баннеров c фильтрацией по фиче и/или тегу в формате NDJSON или CSV
+	// (GET /banner/export)
+	BannerExport(w http.ResponseWriter, r *http.Request, params BannerExportParams)
+	// Загрузка баннеров в формате NDJSON или CSV одной транзакцией
+	// (POST /banner/import)
+	BannerImport(w http.ResponseWriter, r *http.Request, params BannerImportParams)
+	// Удаление баннера по идентификатору
+	// (DELETE /banner/{id})
+	BannerIdDelete(w http.ResponseWriter, r *http.Request, id int, params BannerIdDeleteParams)
+	// Обновление содержимого баннера
+	// (PATCH /banner/{id})
+	BannerIdPatch(w http.ResponseWriter, r *http.Request, id int, params BannerIdPatchParams)
+	// Ревизии баннера и история переходов
+	// (GET /banner/{id}/history)
+	BannerHistoryGet(w http.ResponseWriter, r *http.Request, id int, params BannerHistoryGetParams)
+	// Выпуск подписанной ссылки предпросмотра баннера
+	// (POST /banner/{id}/preview)
+	BannerPreviewPost(w http.ResponseWriter, r *http.Request, id int, params BannerPreviewPostParams)
+	// Перевод ревизии баннера в следующий статус
+	// (POST /banner/{id}/transitions)
+	BannerTransitionPost(w http.ResponseWriter, r *http.Request, id int, params BannerTransitionPostParams)
+	// Удаление JSON Schema фичи
+	// (DELETE /feature/{id}/schema)
+	FeatureSchemaDelete(w http.ResponseWriter, r *http.Request, id int, params FeatureSchemaDeleteParams)
+	// Получение JSON Schema содержимого баннеров фичи
+	// (GET /feature/{id}/schema)
+	FeatureSchemaGet(w http.ResponseWriter, r *http.Request, id int, params FeatureSchemaGetParams)
+	// Установка JSON Schema для content баннеров фичи
+	// (PUT /feature/{id}/schema)
+	FeatureSchemaPut(w http.ResponseWriter, r *http.Request, id int, params FeatureSchemaPutParams)
+	// Проверка существующих баннеров фичи по текущей схеме
+	// (POST /feature/{id}/schema/validate)
+	FeatureSchemaValidate(w http.ResponseWriter, r *http.Request, id int, params FeatureSchemaValidateParams)
+	// Проверка живости процесса
+	// (GET /healthz)
+	Healthz(w http.ResponseWriter, r *http.Request)
+	// Метрики Prometheus
+	// (GET /metrics)
+	Metrics(w http.ResponseWriter, r *http.Request)
+	// Проверка готовности к обработке запросов
+	// (GET /readyz)
+	Readyz(w http.ResponseWriter, r *http.Request)
+	// Получение баннера для пользователя
+	// (GET /user_banner)
+	UserBannerGet(w http.ResponseWriter, r *http.Request, params UserBannerGetParams)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// APIKeysGet operation middleware
+func (siw *ServerInterfaceWrapper) APIKeysGet(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.APIKeysGet(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// APIKeyPost operation middleware
+func (siw *ServerInterfaceWrapper) APIKeyPost(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.APIKeyPost(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// APIKeyDelete operation middleware
+func (siw *ServerInterfaceWrapper) APIKeyDelete(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.APIKeyDelete(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// APIKeyRotatePost operation middleware
+func (siw *ServerInterfaceWrapper) APIKeyRotatePost(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.APIKeyRotatePost(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuditGet operation middleware
+func (siw *ServerInterfaceWrapper) AuditGet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AuditGetParams
+
+	// ------------- Optional query parameter "actor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "actor", r.URL.Query(), &params.Actor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "actor", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "banner_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "banner_id", r.URL.Query(), &params.BannerId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "banner_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "api_key_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "api_key_id", r.URL.Query(), &params.ApiKeyId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "api_key_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "action" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "action", r.URL.Query(), &params.Action)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "action", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuditGet(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// BannerGet operation middleware
+func (siw *ServerInterfaceWrapper) BannerGet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BannerGetParams
+
+	// ------------- Optional query parameter "feature_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "feature_id", r.URL.Query(), &params.FeatureId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "feature_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "tag_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tag_id", r.URL.Query(), &params.TagId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "tag_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BannerGet(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// BannerPost operation middleware
+func (siw *ServerInterfaceWrapper) BannerPost(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BannerPostParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BannerPost(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// BannerExport operation middleware
+func (siw *ServerInterfaceWrapper) BannerExport(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BannerExportParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "feature_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "feature_id", r.URL.Query(), &params.FeatureId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "feature_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "tag_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tag_id", r.URL.Query(), &params.TagId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "tag_id", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BannerExport(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// BannerImport operation middleware
+func (siw *ServerInterfaceWrapper) BannerImport(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BannerImportParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "dry_run" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dry_run", r.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "dry_run", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BannerImport(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// BannerIdDelete operation middleware
+func (siw *ServerInterfaceWrapper) BannerIdDelete(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BannerIdDeleteParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BannerIdDelete(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// BannerIdPatch operation middleware
+func (siw *ServerInterfaceWrapper) BannerIdPatch(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BannerIdPatchParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BannerIdPatch(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// BannerHistoryGet operation middleware
+func (siw *ServerInterfaceWrapper) BannerHistoryGet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BannerHistoryGetParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BannerHistoryGet(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// BannerPreviewPost operation middleware
+func (siw *ServerInterfaceWrapper) BannerPreviewPost(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BannerPreviewPostParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BannerPreviewPost(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// BannerTransitionPost operation middleware
+func (siw *ServerInterfaceWrapper) BannerTransitionPost(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BannerTransitionPostParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BannerTransitionPost(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// FeatureSchemaDelete operation middleware
+func (siw *ServerInterfaceWrapper) FeatureSchemaDelete(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params FeatureSchemaDeleteParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.FeatureSchemaDelete(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// FeatureSchemaGet operation middleware
+func (siw *ServerInterfaceWrapper) FeatureSchemaGet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params FeatureSchemaGetParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.FeatureSchemaGet(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// FeatureSchemaPut operation middleware
+func (siw *ServerInterfaceWrapper) FeatureSchemaPut(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params FeatureSchemaPutParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.FeatureSchemaPut(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// FeatureSchemaValidate operation middleware
+func (siw *ServerInterfaceWrapper) FeatureSchemaValidate(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", mux.Vars(r)["id"], &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params FeatureSchemaValidateParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.FeatureSchemaValidate(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Healthz operation middleware
+func (siw *ServerInterfaceWrapper) Healthz(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Healthz(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Metrics operation middleware
+func (siw *ServerInterfaceWrapper) Metrics(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Metrics(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Readyz operation middleware
+func (siw *ServerInterfaceWrapper) Readyz(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Readyz(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// UserBannerGet operation middleware
+func (siw *ServerInterfaceWrapper) UserBannerGet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UserBannerGetParams
+
+	// ------------- Optional query parameter "tag_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tag_id", r.URL.Query(), &params.TagId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "tag_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "feature_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "feature_id", r.URL.Query(), &params.FeatureId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "feature_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "use_last_revision" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "use_last_revision", r.URL.Query(), &params.UseLastRevision)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "use_last_revision", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "preview_token" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "preview_token", r.URL.Query(), &params.PreviewToken)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "preview_token", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "locale" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "locale", r.URL.Query(), &params.Locale)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "locale", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "template_vars" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "template_vars", r.URL.Query(), &params.TemplateVars)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "template_vars", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Accept-Language" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Accept-Language")]; found {
+		var AcceptLanguage string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Accept-Language", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Accept-Language", valueList[0], &AcceptLanguage, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Accept-Language", Err: err})
+			return
+		}
+
+		params.AcceptLanguage = &AcceptLanguage
+
+	}
+
+	// ------------- Optional header parameter "token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("token")]; found {
+		var Token string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "token", valueList[0], &Token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+			return
+		}
+
+		params.Token = &Token
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UserBannerGet(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+type UnescapedCookieParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnescapedCookieParamError) Error() string {
+	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
+}
+
+func (e *UnescapedCookieParamError) Unwrap() error {
+	return e.Err
+}
+
+type UnmarshalingParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnmarshalingParamError) Error() string {
+	return fmt.Sprintf("Error unmarshaling parameter %s as JSON: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *UnmarshalingParamError) Unwrap() error {
+	return e.Err
+}
+
+type RequiredParamError struct {
+	ParamName string
+}
+
+func (e *RequiredParamError) Error() string {
+	return fmt.Sprintf("Query argument %s is required, but not found", e.ParamName)
+}
+
+type RequiredHeaderError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *RequiredHeaderError) Error() string {
+	return fmt.Sprintf("Header parameter %s is required, but not found", e.ParamName)
+}
+
+func (e *RequiredHeaderError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidParamFormatError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *InvalidParamFormatError) Error() string {
+	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *InvalidParamFormatError) Unwrap() error {
+	return e.Err
+}
+
+type TooManyValuesForParamError struct {
+	ParamName string
+	Count     int
+}
+
+func (e *TooManyValuesForParamError) Error() string {
+	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
+}
+
+// Handler creates http.Handler with routing matching OpenAPI spec.
+func Handler(si ServerInterface) http.Handler {
+	return HandlerWithOptions(si, GorillaServerOptions{})
+}
+
+type GorillaServerOptions struct {
+	BaseURL          string
+	BaseRouter       *mux.Router
+	Middlewares      []MiddlewareFunc
+	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
+func HandlerFromMux(si ServerInterface, r *mux.Router) http.Handler {
+	return HandlerWithOptions(si, GorillaServerOptions{
+		BaseRouter: r,
+	})
+}
+
+func HandlerFromMuxWithBaseURL(si ServerInterface, r *mux.Router, baseURL string) http.Handler {
+	return HandlerWithOptions(si, GorillaServerOptions{
+		BaseURL:    baseURL,
+		BaseRouter: r,
+	})
+}
+
+// HandlerWithOptions creates http.Handler with additional options
+func HandlerWithOptions(si ServerInterface, options GorillaServerOptions) http.Handler {
+	r := options.BaseRouter
+
+	if r == nil {
+		r = mux.NewRouter()
+	}
+	if options.ErrorHandlerFunc == nil {
+		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandlerFunc:   options.ErrorHandlerFunc,
+	}
+
+	r.HandleFunc(options.BaseURL+"/api_keys", wrapper.APIKeysGet).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/api_keys", wrapper.APIKeyPost).Methods("POST")
+
+	r.HandleFunc(options.BaseURL+"/api_keys/{id}", wrapper.APIKeyDelete).Methods("DELETE")
+
+	r.HandleFunc(options.BaseURL+"/api_keys/{id}/rotate", wrapper.APIKeyRotatePost).Methods("POST")
+
+	r.HandleFunc(options.BaseURL+"/audit", wrapper.AuditGet).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/banner", wrapper.BannerGet).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/banner", wrapper.BannerPost).Methods("POST")
+
+	r.HandleFunc(options.BaseURL+"/banner/export", wrapper.BannerExport).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/banner/import", wrapper.BannerImport).Methods("POST")
+
+	r.HandleFunc(options.BaseURL+"/banner/{id}", wrapper.BannerIdDelete).Methods("DELETE")
+
+	r.HandleFunc(options.BaseURL+"/banner/{id}", wrapper.BannerIdPatch).Methods("PATCH")
+
+	r.HandleFunc(options.BaseURL+"/banner/{id}/history", wrapper.BannerHistoryGet).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/banner/{id}/preview", wrapper.BannerPreviewPost).Methods("POST")
+
+	r.HandleFunc(options.BaseURL+"/banner/{id}/transitions", wrapper.BannerTransitionPost).Methods("POST")
+
+	r.HandleFunc(options.BaseURL+"/feature/{id}/schema", wrapper.FeatureSchemaDelete).Methods("DELETE")
+
+	r.HandleFunc(options.BaseURL+"/feature/{id}/schema", wrapper.FeatureSchemaGet).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/feature/{id}/schema", wrapper.FeatureSchemaPut).Methods("PUT")
+
+	r.HandleFunc(options.BaseURL+"/feature/{id}/schema/validate", wrapper.FeatureSchemaValidate).Methods("POST")
+
+	r.HandleFunc(options.BaseURL+"/healthz", wrapper.Healthz).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/metrics", wrapper.Metrics).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/readyz", wrapper.Readyz).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/user_banner", wrapper.UserBannerGet).Methods("GET")
+
+	return r
+}
@@ -0,0 +1,10 @@
+// Package api содержит спецификацию OpenAPI сервиса, по ней проверяются запросы и ответы.
+// Модели и интерфейс сервера в api.gen.go генерируются из api.yaml: go generate ./api
+package api
+
+import _ "embed"
+
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen -config oapi-codegen.yaml api.yaml
+
+//go:embed api.yaml
+var Spec []byte
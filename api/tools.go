@@ -0,0 +1,6 @@
+//go:build tools
+
+// Версия генератора фиксируется в go.mod
+package api
+
+import _ "github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen"
@@ -1,23 +1,105 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"my_app/internal/apikey"
+	"my_app/internal/auth"
 	"my_app/internal/cache"
+	"my_app/internal/config"
 	"my_app/internal/db"
+	"my_app/internal/locale"
+	"my_app/internal/logging"
+	"my_app/internal/preview"
+	"my_app/internal/ratelimit"
+	"my_app/internal/rbac"
 	"my_app/internal/server"
-	"net/http"
+	"my_app/internal/templating"
+	"my_app/internal/tracing"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 )
 
 func main() {
-	log.Printf("Server started")
+	args := os.Args[1:]
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		printConfig(args[2:])
+		return
+	}
 
-	db.InitDB()
-	defer db.CloseDB()
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logging.InitLogging(cfg.Logging)
+	err = run(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("server stopped")
+}
+
+// printConfig выводит действующие настройки без секретов, при ошибках проверки
+// они печатаются в stderr и команда завершается с ненулевым кодом
+func printConfig(args []string) {
+	cfg, err := config.Parse(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = config.Print(os.Stdout, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run возвращает управление после остановки сервера, чтобы отложенные
+// закрытия подключений и отправка спанов выполнились
+func run(cfg *config.Config) error {
+	tracing.InitTracing(cfg.Tracing)
+	defer tracing.CloseTracing()
+
+	auth.InitAuth(cfg.Auth)
+	rbac.InitRBAC(cfg.RBAC)
 
-	cache.InitCache()
-	defer cache.CloseCache()
+	db.InitDB(cfg.Database)
+	defer closeWithLog("database", db.CloseDB)
+	apikey.InitAPIKeys(cfg.APIKeys, db.APIKeyStore{})
 
+	cache.InitCache(cfg.Cache)
+	defer closeWithLog("cache", cache.CloseCache)
+	ratelimit.InitRateLimit(cfg.RateLimit)
+
+	locale.InitLocale(cfg.Locale)
+	templating.InitTemplating(cfg.Templating)
+	if cfg.Features.Preview {
+		preview.InitPreview(cfg.Preview)
+	}
+
+	server.InitServer(cfg.Server, cfg.Features)
 	router := server.NewRouter()
 
-	log.Fatal(http.ListenAndServe(":8080", router))
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.Server.Port))
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	slog.Info("server started", "addr", listener.Addr().String(), "tls", cfg.Server.TLSCertFile != "")
+	return server.Serve(ctx, listener, router)
+}
+
+// closeWithLog закрывает подключение при остановке, ошибка закрытия уже не мешает завершению
+func closeWithLog(name string, close func() error) {
+	if err := close(); err != nil {
+		slog.Error("failed to close connection", "connection", name, "error", err)
+	}
 }
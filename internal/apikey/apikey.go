@@ -0,0 +1,177 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"my_app/internal/apperr"
+	"my_app/internal/auth"
+	"my_app/internal/background"
+	"my_app/internal/config"
+	"my_app/internal/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	keyPrefix       = "bsk_"
+	defaultCacheTTL = time.Minute
+	// Как часто обновлять last_used_at одного ключа
+	touchInterval = time.Minute
+	maxCacheSize  = 10000
+)
+
+var (
+	ErrInvalidKey = errors.New("invalid api key")
+	ErrExpiredKey = errors.New("api key expired")
+	ErrRevokedKey = errors.New("api key revoked")
+)
+
+// Store - хранилище ключей. Для неизвестного хэша возвращает ошибку вида apperr.ErrNotFound
+type Store interface {
+	GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int32, usedAt time.Time) error
+}
+
+type cacheEntry struct {
+	principal *auth.Principal
+	err       error
+	keyId     int32
+	expiresAt *time.Time
+	fetchedAt time.Time
+	touchedAt time.Time
+}
+
+var (
+	store    Store
+	mu       sync.Mutex
+	entries  = make(map[string]*cacheEntry)
+	cacheTTL = defaultCacheTTL
+)
+
+// InitAPIKeys задает хранилище ключей и сбрасывает кэш проверенных ключей
+func InitAPIKeys(cfg config.APIKeys, keys Store) {
+	store = keys
+	mu.Lock()
+	entries = make(map[string]*cacheEntry)
+	mu.Unlock()
+	cacheTTL = cfg.CacheTTL
+	if cacheTTL <= 0 {
+		cacheTTL = defaultCacheTTL
+	}
+}
+
+// Generate возвращает новый ключ, его короткий префикс для поиска в списке и хэш для хранения
+func Generate() (key string, prefix string, hash string, err error) {
+	secret := make([]byte, 32)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return "", "", "", err
+	}
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:len(keyPrefix)+8], Hash(key), nil
+}
+
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, keyPrefix)
+}
+
+// Authenticate проверяет ключ по хранилищу, результат кэшируется в памяти на API_KEY_CACHE_TTL
+func Authenticate(ctx context.Context, key string) (*auth.Principal, error) {
+	hash := Hash(key)
+	now := time.Now()
+
+	mu.Lock()
+	entry, ok := entries[hash]
+	mu.Unlock()
+	if !ok || now.Sub(entry.fetchedAt) > cacheTTL {
+		var err error
+		entry, err = load(ctx, hash, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	if entry.expiresAt != nil && !now.Before(*entry.expiresAt) {
+		return nil, ErrExpiredKey
+	}
+	touch(ctx, hash, entry, now)
+	return entry.principal, nil
+}
+
+func load(ctx context.Context, hash string, now time.Time) (*cacheEntry, error) {
+	entry := &cacheEntry{fetchedAt: now}
+	key, err := store.GetAPIKeyByHash(ctx, hash)
+	switch {
+	case errors.Is(err, apperr.ErrNotFound):
+		// Неизвестные ключи тоже кэшируются, чтобы перебор не нагружал базу
+		entry.err = ErrInvalidKey
+	case err != nil:
+		return nil, err
+	case key.RevokedAt != nil:
+		entry.err = ErrRevokedKey
+	default:
+		entry.keyId = key.ID
+		entry.expiresAt = key.ExpiresAt
+		entry.principal = &auth.Principal{
+			Subject: "api_key:" + key.Name,
+			Tenant:  key.Tenant,
+			Roles:   []string{key.Role},
+			Scopes:  key.Scopes,
+		}
+		if key.LastUsedAt != nil {
+			entry.touchedAt = *key.LastUsedAt
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(entries) >= maxCacheSize {
+		for cached, e := range entries {
+			if now.Sub(e.fetchedAt) > cacheTTL {
+				delete(entries, cached)
+			}
+		}
+	}
+	if len(entries) < maxCacheSize {
+		entries[hash] = entry
+	}
+	return entry, nil
+}
+
+func touch(ctx context.Context, hash string, entry *cacheEntry, now time.Time) {
+	mu.Lock()
+	if now.Sub(entry.touchedAt) < touchInterval {
+		mu.Unlock()
+		return
+	}
+	entry.touchedAt = now
+	mu.Unlock()
+
+	id := entry.keyId
+	background.Go(ctx, func(ctx context.Context) {
+		err := store.TouchAPIKey(ctx, id, now)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to update api key last use", "key_id", id, "error", err)
+		}
+	})
+}
+
+// Forget удаляет ключ из кэша этой реплики после ротации или отзыва
+func Forget(hash string) {
+	mu.Lock()
+	delete(entries, hash)
+	mu.Unlock()
+}
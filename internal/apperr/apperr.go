@@ -0,0 +1,63 @@
+package apperr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Виды ошибок, по которым обработчики выбирают HTTP статус. Конкретные ошибки
+// создаются через New и проверяются как errors.Is(err, db.ErrBannerNotFound),
+// так и errors.Is(err, apperr.ErrNotFound)
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrValidation  = errors.New("validation failed")
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+type Error struct {
+	kind    error
+	message string
+	err     error
+}
+
+// New создает ошибку вида kind с текстом message
+func New(kind error, message string) *Error {
+	return &Error{kind: kind, message: message}
+}
+
+// Wrap относит err к виду kind, текст ошибки не меняется
+func Wrap(kind error, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{kind: kind, message: err.Error(), err: err}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// HTTPStatus возвращает статус ответа для err, ошибки без вида - внутренние
+func HTTPStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnavailable), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
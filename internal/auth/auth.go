@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"my_app/internal/config"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultRoleClaim   = "role"
+	defaultTenantClaim = "tenant"
+	// Тенант токенов и данных без явного тенанта
+	DefaultTenant = "default"
+)
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+var (
+	ErrNoKey         = errors.New("no key to verify token")
+	ErrInvalidTenant = errors.New("invalid tenant")
+)
+
+type Principal struct {
+	// Идентификатор из claim sub или имя API ключа
+	Subject string
+	// Тенант, к данным которого относится запрос
+	Tenant string
+	Roles  []string
+	Scopes []string
+}
+
+// ValidTenant проверяет идентификатор тенанта, он входит в ключи кэша
+func ValidTenant(tenant string) bool {
+	return tenantPattern.MatchString(tenant)
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type authConfig struct {
+	hmacSecret  []byte
+	rsaKeys     map[string]*rsa.PublicKey
+	audience    string
+	issuer      string
+	roleClaim   string
+	tenantClaim string
+	leeway      time.Duration
+}
+
+var cfg authConfig
+
+func InitAuth(settings config.Auth) {
+	cfg = authConfig{
+		hmacSecret:  []byte(settings.HS256Secret),
+		rsaKeys:     make(map[string]*rsa.PublicKey),
+		audience:    settings.Audience,
+		issuer:      settings.Issuer,
+		roleClaim:   settings.RoleClaim,
+		tenantClaim: settings.TenantClaim,
+		leeway:      settings.Leeway,
+	}
+	if cfg.roleClaim == "" {
+		cfg.roleClaim = defaultRoleClaim
+	}
+	if cfg.tenantClaim == "" {
+		cfg.tenantClaim = defaultTenantClaim
+	}
+	if value := settings.RS256PublicKey; value != "" {
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(value))
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg.rsaKeys[""] = key
+	}
+	if path := settings.JWKSFile; path != "" {
+		err := loadJWKS(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if len(cfg.hmacSecret) == 0 && len(cfg.rsaKeys) == 0 {
+		log.Fatal("hs256_secret, rs256_public_key or jwks_file must be set")
+	}
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func loadJWKS(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	err = json.Unmarshal(data, &set)
+	if err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+	for _, key := range set.Keys {
+		// Ключи шифрования и не RSA ключи не используются для подписи токенов
+		if key.Kty != "RSA" || key.Use == "enc" || key.Alg != "" && key.Alg != "RS256" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return fmt.Errorf("jwks key %q: %w", key.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return fmt.Errorf("jwks key %q: %w", key.Kid, err)
+		}
+		cfg.rsaKeys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return nil
+}
+
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if len(cfg.hmacSecret) == 0 {
+			return nil, ErrNoKey
+		}
+		return cfg.hmacSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := cfg.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		// Токен без kid проверяется единственным ключом
+		if kid == "" && len(cfg.rsaKeys) == 1 {
+			for _, key := range cfg.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, ErrNoKey
+	}
+	return nil, ErrNoKey
+}
+
+// Verify проверяет подпись, exp, nbf, aud и iss токена и возвращает его владельца
+func Verify(tokenString string) (*Principal, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.leeway),
+	}
+	if cfg.audience != "" {
+		options = append(options, jwt.WithAudience(cfg.audience))
+	}
+	if cfg.issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.issuer))
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, keyFunc, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	var principal Principal
+	principal.Subject, _ = claims.GetSubject()
+	switch roles := claims[cfg.roleClaim].(type) {
+	case string:
+		principal.Roles = []string{roles}
+	case []interface{}:
+		for _, role := range roles {
+			if role, ok := role.(string); ok {
+				principal.Roles = append(principal.Roles, role)
+			}
+		}
+	}
+	principal.Tenant = DefaultTenant
+	if tenant, ok := claims[cfg.tenantClaim]; ok {
+		value, _ := tenant.(string)
+		if !ValidTenant(value) {
+			return nil, ErrInvalidTenant
+		}
+		principal.Tenant = value
+	}
+	// Scopes в формате OAuth 2.0: строка через пробел
+	if scope, ok := claims["scope"].(string); ok {
+		principal.Scopes = strings.Fields(scope)
+	}
+	return &principal, nil
+}
@@ -0,0 +1,70 @@
+package background
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	tasks sync.WaitGroup
+
+	stopMu sync.Mutex
+	// Закрывается в Wait, периодические задачи по нему завершаются
+	stopping = make(chan struct{})
+)
+
+// Go запускает задачу, которая продолжается после ответа на запрос. Ее контекст
+// не отменяется вместе с запросом, но сохраняет трассировку и идентификатор запроса
+func Go(ctx context.Context, task func(ctx context.Context)) {
+	ctx = context.WithoutCancel(ctx)
+	tasks.Add(1)
+	go func() {
+		defer tasks.Done()
+		task(ctx)
+	}()
+}
+
+// Every выполняет задачу каждые interval, пока не отменен ctx или не вызван Wait.
+// Текущее выполнение задачи при остановке не прерывается
+func Every(ctx context.Context, interval time.Duration, task func(ctx context.Context)) {
+	stopMu.Lock()
+	stop := stopping
+	stopMu.Unlock()
+	tasks.Add(1)
+	go func() {
+		defer tasks.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				task(ctx)
+			}
+		}
+	}()
+}
+
+// Wait останавливает периодические задачи и ждет завершения запущенных, но не дольше, чем живет ctx
+func Wait(ctx context.Context) error {
+	stopMu.Lock()
+	close(stopping)
+	stopping = make(chan struct{})
+	stopMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		tasks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,135 @@
+package breaker
+
+import (
+	"context"
+	"my_app/internal/apperr"
+	"my_app/internal/metrics"
+	"sync"
+	"time"
+)
+
+// State - состояние автомата, значение публикуется в метрике circuit_breaker_state
+type State int
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "open"
+	}
+}
+
+var ErrOpen = apperr.New(apperr.ErrUnavailable, "circuit breaker is open")
+
+// Breaker размыкается после threshold неудачных вызовов подряд и сразу отклоняет вызовы
+// в течение cooldown. Затем пропускает один пробный вызов: удачный замыкает автомат,
+// неудачный снова размыкает его
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func New(name string, threshold int, cooldown time.Duration) *Breaker {
+	b := &Breaker{name: name, threshold: threshold, cooldown: cooldown}
+	metrics.BreakerState.WithLabelValues(name).Set(float64(Closed))
+	return b
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow разрешает вызов или возвращает ErrOpen. После разрешенного вызова нужно вызвать Record
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Open:
+		if time.Since(b.openedAt) < b.cooldown {
+			break
+		}
+		b.setState(HalfOpen)
+		b.probing = true
+		return nil
+	case HalfOpen:
+		if b.probing {
+			break
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+	metrics.BreakerRejections.WithLabelValues(b.name).Inc()
+	return ErrOpen
+}
+
+// Record учитывает результат вызова, failure - ошибка говорит о недоступности сервиса
+func (b *Breaker) Record(failure bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failure {
+		// Вызов, начатый до размыкания, не закрывает автомат раньше пробного
+		if b.state != Open {
+			b.failures = 0
+			b.probing = false
+			b.setState(Closed)
+		}
+		return
+	}
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.failures = 0
+		b.probing = false
+		b.openedAt = time.Now()
+		b.setState(Open)
+	}
+}
+
+// State возвращает текущее состояние. Open означает, что вызовы отклоняются прямо сейчас
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && time.Since(b.openedAt) >= b.cooldown {
+		return HalfOpen
+	}
+	return b.state
+}
+
+func (b *Breaker) setState(state State) {
+	b.state = state
+	metrics.BreakerState.WithLabelValues(b.name).Set(float64(state))
+}
+
+// Retry повторяет call не больше retries раз, пока isFailure считает ошибку временной,
+// пауза перед каждой попыткой растет линейно от backoff
+func Retry(ctx context.Context, retries int, backoff time.Duration, isFailure func(error) bool, call func() error) error {
+	err := call()
+	for attempt := 1; attempt <= retries && err != nil && isFailure(err); attempt++ {
+		timer := time.NewTimer(time.Duration(attempt) * backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = call()
+	}
+	return err
+}
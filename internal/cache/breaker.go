@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"my_app/internal/breaker"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// cacheBreaker создается в InitCache с порогом и паузой из настроек
+var cacheBreaker *breaker.Breaker
+
+// Available сообщает, что Redis подключен, не отклоняется автоматом и к нему стоит обращаться
+func Available() bool {
+	return cacheBreaker != nil && cacheBreaker.State() != breaker.Open
+}
+
+// breakerHook пропускает команды к Redis через автомат, с ним клиент перестает ждать
+// таймаутов недоступного Redis. Повторные попытки go-redis выполняются внутри одного вызова
+type breakerHook struct{}
+
+func (breakerHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (breakerHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := cacheBreaker.Allow(); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		err := next(ctx, cmd)
+		cacheBreaker.Record(isFailure(err))
+		return err
+	}
+}
+
+func (breakerHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := cacheBreaker.Allow(); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		err := next(ctx, cmds)
+		cacheBreaker.Record(isFailure(err))
+		return err
+	}
+}
+
+// isFailure отделяет недоступность Redis от ответов, которые Redis вернул сам
+func isFailure(err error) bool {
+	var redisErr redis.Error
+	switch {
+	case err == nil, errors.Is(err, redis.Nil), errors.Is(err, context.Canceled):
+		return false
+	case errors.As(err, &redisErr):
+		return false
+	}
+	return true
+}
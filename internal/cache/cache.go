@@ -3,81 +3,173 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"my_app/internal/apperr"
+	"my_app/internal/background"
+	"my_app/internal/breaker"
+	"my_app/internal/config"
+	"my_app/internal/locale"
+	"my_app/internal/metrics"
 	"my_app/internal/models"
-	"os"
+	"my_app/internal/tracing"
+	"net"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-var ctx = context.Background()
 var rdb *redis.Client
 var ttl time.Duration
 
-func InitCache() {
-	host := os.Getenv("CACHE_HOST")
-	port := os.Getenv("CACHE_PORT")
-	password := os.Getenv("CACHE_PASSWORD")
-	var err error
-	ttl, err = time.ParseDuration(os.Getenv("CACHE_TTL"))
-	if err != nil {
-		log.Fatal(err)
+func InitCache(cfg config.Cache) {
+	ttl = cfg.TTL
+	// Для go-redis 0 означает число попыток по умолчанию, а -1 отключает их
+	maxRetries := cfg.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = -1
 	}
+	cacheBreaker = breaker.New("redis", cfg.BreakerThreshold, cfg.BreakerCooldown)
 	rdb = redis.NewClient(&redis.Options{
-		Addr:     host + ":" + port,
-		Password: password,
-		DB:       0,
+		Addr:         net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Password:     cfg.Password,
+		DB:           0,
+		PoolSize:     cfg.PoolSize,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		MaxRetries:   maxRetries,
+		// Таймауты берутся и из контекста запроса, если он истекает раньше
+		ContextTimeoutEnabled: true,
 	})
+	rdb.AddHook(breakerHook{})
 
-	_, err = rdb.Ping(ctx).Result()
+	_, err := rdb.Ping(context.Background()).Result()
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
-func CloseCache() {
-	err := rdb.Close()
-	if err != nil {
-		log.Fatalf("Error closing Redis connection: %v", err)
+var tracer = tracing.Tracer("cache")
+
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemRedis))
+}
+
+var (
+	ErrNotInitialized = apperr.New(apperr.ErrUnavailable, "cache is not initialized")
+	// ErrMiss - баннера нет в кэше, его нужно прочитать из базы
+	ErrMiss = errors.New("banner is not in cache")
+)
+
+func Ping(ctx context.Context) error {
+	if rdb == nil {
+		return ErrNotInitialized
 	}
+	return rdb.Ping(ctx).Err()
 }
 
-func GetBannerFromCache(featureId *int, tagId *int) (*models.BannerExpanded, error) {
-	var banner models.BannerExpanded
-	cacheKey := fmt.Sprintf("banner:%d:%d", *featureId, *tagId)
-	result, err := rdb.Get(ctx, cacheKey).Result()
-	if err == redis.Nil {
-		return nil, fmt.Errorf("no banner found")
+// Client возвращает подключение к Redis для других подсистем, например лимитов запросов
+func Client() *redis.Client {
+	return rdb
+}
+
+func CloseCache() error {
+	return rdb.Close()
+}
+
+// bannerCacheKey начинается с тенанта, id фич и тегов у разных тенантов пересекаются.
+// Локаль нормализуется: баннер сохраняется под "pt-BR", а ищется по кандидатам вида "pt-br"
+func bannerCacheKey(tenant string, featureId int, tagId int, tag string) string {
+	if tag == "" {
+		return fmt.Sprintf("banner:%s:%d:%d", tenant, featureId, tagId)
 	}
-	err = json.Unmarshal([]byte(result), &banner)
+	return fmt.Sprintf("banner:%s:%d:%d:%s", tenant, featureId, tagId, locale.Normalize(tag))
+}
+
+// GetBannerFromCache ищет баннер по ключам всех локалей-кандидатов одним запросом.
+// Попадание засчитывается, только если локаль из кэша совпадает с той,
+// что была бы выбрана по полному списку локалей баннера.
+func GetBannerFromCache(ctx context.Context, tenant string, featureId *int, tagId *int, locales []string) (*models.LocalizedBanner, error) {
+	ctx, span := startSpan(ctx, "cache.get")
+	defer span.End()
+	keys := make([]string, 0, len(locales)+1)
+	for _, tag := range locales {
+		keys = append(keys, bannerCacheKey(tenant, *featureId, *tagId, tag))
+	}
+	keys = append(keys, bannerCacheKey(tenant, *featureId, *tagId, ""))
+	results, err := rdb.MGet(ctx, keys...).Result()
 	if err != nil {
+		metrics.CacheRequests.WithLabelValues("error").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
-	log.Println("Loaded from cache")
-	return &banner, nil
+	for i, result := range results {
+		value, ok := result.(string)
+		if !ok {
+			continue
+		}
+		var banner models.LocalizedBanner
+		err = json.Unmarshal([]byte(value), &banner)
+		if err != nil {
+			metrics.CacheRequests.WithLabelValues("error").Inc()
+			return nil, err
+		}
+		if banner.Locale != "" {
+			resolved, _ := locale.Resolve(locales, banner.Locales)
+			if resolved != banner.Locale {
+				break
+			}
+		}
+		slog.DebugContext(ctx, "loaded from cache", "key", keys[i])
+		metrics.CacheRequests.WithLabelValues("hit").Inc()
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		return &banner, nil
+	}
+	metrics.CacheRequests.WithLabelValues("miss").Inc()
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	return nil, ErrMiss
 }
 
-func SaveBannerToCacheAsync(featureId *int, tagId *int, banner *models.BannerExpanded) {
-	go func(featureId int, tagId int, banner models.BannerExpanded) {
-		err := SaveBannerToCache(&featureId, &tagId, &banner)
+// SaveBannerToCacheAsync пишет в кэш после ответа, запись остается в трассировке запроса,
+// но не отменяется вместе с ним. При остановке сервер дожидается таких записей
+func SaveBannerToCacheAsync(ctx context.Context, tenant string, featureId *int, tagId *int, banner *models.LocalizedBanner) {
+	metrics.CacheAsyncWrites.Inc()
+	metrics.CacheAsyncWritesInFlight.Inc()
+	featureIdCopy, tagIdCopy, bannerCopy := *featureId, *tagId, *banner
+	background.Go(ctx, func(ctx context.Context) {
+		defer metrics.CacheAsyncWritesInFlight.Dec()
+		err := SaveBannerToCache(ctx, tenant, &featureIdCopy, &tagIdCopy, &bannerCopy)
 		if err != nil {
-			log.Printf("Failed to save banner to cache: %v", err)
+			slog.WarnContext(ctx, "failed to save banner to cache", "error", err)
 		}
-	}(*featureId, *tagId, *banner)
+	})
 }
 
-func SaveBannerToCache(featureId *int, tagId *int, banner *models.BannerExpanded) error {
-	cacheKey := fmt.Sprintf("banner:%d:%d", *featureId, *tagId)
+func SaveBannerToCache(ctx context.Context, tenant string, featureId *int, tagId *int, banner *models.LocalizedBanner) error {
+	ctx, span := startSpan(ctx, "cache.set")
+	defer span.End()
+	cacheKey := bannerCacheKey(tenant, *featureId, *tagId, banner.Locale)
 	bannerJson, err := json.Marshal(banner)
 	if err != nil {
 		return err
 	}
 	err = rdb.Set(ctx, cacheKey, bannerJson, ttl).Err()
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
-	log.Println("Saved to cache")
+	slog.DebugContext(ctx, "saved to cache", "key", cacheKey)
 	return err
 }
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"time"
+)
+
+// Config - все настройки сервиса. Значение поля берется по возрастанию приоритета:
+// default, YAML файл (ключ yaml), переменная окружения (env), флаг командной строки
+// (путь из ключей yaml через точку, например -database.host)
+type Config struct {
+	Server     Server     `yaml:"server"`
+	Database   Database   `yaml:"database"`
+	Cache      Cache      `yaml:"cache"`
+	Auth       Auth       `yaml:"auth"`
+	APIKeys    APIKeys    `yaml:"api_keys"`
+	RBAC       RBAC       `yaml:"rbac"`
+	RateLimit  RateLimit  `yaml:"rate_limit"`
+	Tracing    Tracing    `yaml:"tracing"`
+	Logging    Logging    `yaml:"logging"`
+	Preview    Preview    `yaml:"preview"`
+	Locale     Locale     `yaml:"locale"`
+	Templating Templating `yaml:"templating"`
+	Features   Features   `yaml:"features"`
+}
+
+type Server struct {
+	Port int `yaml:"port" env:"APP_PORT" default:"8080"`
+	// TLS включается, если заданы сертификат и ключ
+	TLSCertFile     string        `yaml:"tls_cert_file" env:"TLS_CERT_FILE"`
+	TLSKeyFile      string        `yaml:"tls_key_file" env:"TLS_KEY_FILE"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" default:"10s"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" default:"2m"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	HealthTimeout   time.Duration `yaml:"health_timeout" env:"HEALTH_TIMEOUT" default:"2s"`
+	// Проверка запросов и ответов по api/api.yaml, ответы проверяются в тестах
+	ValidateRequests  bool `yaml:"validate_requests" env:"VALIDATE_REQUESTS" default:"true"`
+	ValidateResponses bool `yaml:"validate_responses" env:"VALIDATE_RESPONSES"`
+}
+
+type Database struct {
+	Host            string        `yaml:"host" env:"DB_HOST"`
+	Port            int           `yaml:"port" env:"DB_PORT" default:"5432"`
+	User            string        `yaml:"user" env:"DB_USER"`
+	Password        string        `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name            string        `yaml:"name" env:"DB_NAME"`
+	SSLMode         string        `yaml:"ssl_mode" env:"DB_SSLMODE" default:"disable"`
+	SSLRootCert     string        `yaml:"ssl_root_cert" env:"DB_SSLROOTCERT"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"10"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+	IdempotencyTTL  time.Duration `yaml:"idempotency_ttl" env:"IDEMPOTENCY_TTL" default:"24h"`
+	// Реплика для чтения в формате DSN lib/pq, пустая - все запросы идут в основную базу
+	ReplicaDSN string `yaml:"replica_dsn" env:"DB_REPLICA_DSN" secret:"true"`
+	// Допустимое отставание реплики для чтения с use_last_revision
+	ReplicaMaxLag time.Duration `yaml:"replica_max_lag" env:"DB_REPLICA_MAX_LAG" default:"1s"`
+	// Таймаут одного запроса к базе, выгрузка и загрузка баннеров им не ограничены
+	QueryTimeout time.Duration `yaml:"query_timeout" env:"DB_QUERY_TIMEOUT" default:"5s"`
+	// Повторные попытки подключения, запросы не повторяются
+	ConnectRetries int `yaml:"connect_retries" env:"DB_CONNECT_RETRIES" default:"2"`
+	// После breaker_threshold ошибок подряд запросы отклоняются на breaker_cooldown
+	BreakerThreshold int           `yaml:"breaker_threshold" env:"DB_BREAKER_THRESHOLD" default:"5"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" env:"DB_BREAKER_COOLDOWN" default:"10s"`
+}
+
+type Cache struct {
+	Host     string        `yaml:"host" env:"CACHE_HOST"`
+	Port     int           `yaml:"port" env:"CACHE_PORT" default:"6379"`
+	Password string        `yaml:"password" env:"CACHE_PASSWORD" secret:"true"`
+	TTL      time.Duration `yaml:"ttl" env:"CACHE_TTL" default:"5m"`
+	// 0 - размер пула go-redis по умолчанию, 10 соединений на процессор
+	PoolSize     int           `yaml:"pool_size" env:"CACHE_POOL_SIZE"`
+	DialTimeout  time.Duration `yaml:"dial_timeout" env:"CACHE_DIAL_TIMEOUT" default:"5s"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" env:"CACHE_READ_TIMEOUT" default:"500ms"`
+	WriteTimeout time.Duration `yaml:"write_timeout" env:"CACHE_WRITE_TIMEOUT" default:"500ms"`
+	// 0 - без повторных попыток
+	MaxRetries       int           `yaml:"max_retries" env:"CACHE_MAX_RETRIES" default:"1"`
+	BreakerThreshold int           `yaml:"breaker_threshold" env:"CACHE_BREAKER_THRESHOLD" default:"5"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" env:"CACHE_BREAKER_COOLDOWN" default:"5s"`
+}
+
+type Auth struct {
+	HS256Secret    string        `yaml:"hs256_secret" env:"JWT_HS256_SECRET" secret:"true"`
+	RS256PublicKey string        `yaml:"rs256_public_key" env:"JWT_RS256_PUBLIC_KEY"`
+	JWKSFile       string        `yaml:"jwks_file" env:"JWT_JWKS_FILE"`
+	Audience       string        `yaml:"audience" env:"JWT_AUDIENCE"`
+	Issuer         string        `yaml:"issuer" env:"JWT_ISSUER"`
+	RoleClaim      string        `yaml:"role_claim" env:"JWT_ROLE_CLAIM" default:"role"`
+	TenantClaim    string        `yaml:"tenant_claim" env:"JWT_TENANT_CLAIM" default:"tenant"`
+	Leeway         time.Duration `yaml:"leeway" env:"JWT_LEEWAY"`
+}
+
+type APIKeys struct {
+	CacheTTL time.Duration `yaml:"cache_ttl" env:"API_KEY_CACHE_TTL" default:"1m"`
+}
+
+type RBAC struct {
+	RolesFile string `yaml:"roles_file" env:"RBAC_ROLES_FILE"`
+}
+
+type RateLimit struct {
+	// Лимит вида "100/1s" для всех маршрутов, пустой - без лимита
+	Default string `yaml:"default" env:"RATE_LIMIT"`
+	// Лимиты маршрутов вида "UserBannerGet=1000/1m,BannerPost=60/1m"
+	Routes  string `yaml:"routes" env:"RATE_LIMIT_ROUTES"`
+	Backend string `yaml:"backend" env:"RATE_LIMIT_BACKEND" default:"memory"`
+}
+
+type Tracing struct {
+	Exporter    string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER" default:"none"`
+	Endpoint    string `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME" default:"banner-service"`
+	File        string `yaml:"file" env:"TRACE_FILE" default:"traces.json"`
+}
+
+type Logging struct {
+	Level string `yaml:"level" env:"LOG_LEVEL" default:"info"`
+}
+
+type Preview struct {
+	Secret string        `yaml:"secret" env:"PREVIEW_SECRET" secret:"true"`
+	MaxTTL time.Duration `yaml:"max_ttl" env:"PREVIEW_MAX_TTL" default:"24h"`
+}
+
+type Locale struct {
+	Fallback []string `yaml:"fallback" env:"LOCALE_FALLBACK"`
+}
+
+type Templating struct {
+	Vars []string `yaml:"vars" env:"TEMPLATE_VARS"`
+}
+
+// Features включает и выключает группы маршрутов
+type Features struct {
+	Preview bool `yaml:"preview" env:"FEATURE_PREVIEW" default:"true"`
+	// Импорт и экспорт баннеров
+	Bulk    bool `yaml:"bulk" env:"FEATURE_BULK" default:"true"`
+	APIKeys bool `yaml:"api_keys" env:"FEATURE_API_KEYS" default:"true"`
+	Metrics bool `yaml:"metrics" env:"FEATURE_METRICS" default:"true"`
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "<redacted>"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load собирает настройки из значений по умолчанию, YAML файла (-config или CONFIG_FILE),
+// переменных окружения и флагов args и проверяет их
+func Load(args []string) (*Config, error) {
+	cfg, err := Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	err = cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Parse собирает настройки как Load, но не проверяет их
+func Parse(args []string) (*Config, error) {
+	var cfg Config
+	err := walk(&cfg, func(field reflect.StructField, value reflect.Value, path string) error {
+		if def, ok := field.Tag.Lookup("default"); ok {
+			return set(value, def)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Флаги разбираются первыми, чтобы узнать путь к файлу, но применяются последними
+	flags := flag.NewFlagSet("myapp", flag.ContinueOnError)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML config file (CONFIG_FILE)")
+	overrides := make(map[string]string)
+	err = walk(&cfg, func(field reflect.StructField, value reflect.Value, path string) error {
+		usage := path
+		if env := field.Tag.Get("env"); env != "" {
+			usage = env
+		}
+		flags.Func(path, usage, func(s string) error {
+			overrides[path] = s
+			return set(reflect.New(value.Type()).Elem(), s)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	if *file != "" {
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return nil, err
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&cfg)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%s: %w", *file, err)
+		}
+	}
+
+	err = walk(&cfg, func(field reflect.StructField, value reflect.Value, path string) error {
+		env := field.Tag.Get("env")
+		if s := os.Getenv(env); env != "" && s != "" {
+			if err := set(value, s); err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+		}
+		if s, ok := overrides[path]; ok {
+			return set(value, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// walk обходит конечные поля настроек, path - ключи yaml через точку
+func walk(cfg *Config, fn func(field reflect.StructField, value reflect.Value, path string) error) error {
+	var visit func(v reflect.Value, prefix string) error
+	visit = func(v reflect.Value, prefix string) error {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			path := prefix + field.Tag.Get("yaml")
+			if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+				if err := visit(v.Field(i), path+"."); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := fn(field, v.Field(i), path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return visit(reflect.ValueOf(cfg).Elem(), "")
+}
+
+// set разбирает значение из строки: списки через запятую, длительности в формате time.ParseDuration
+func set(value reflect.Value, s string) error {
+	switch {
+	case value.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+	case value.Kind() == reflect.String:
+		value.SetString(s)
+	case value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		value.SetInt(int64(n))
+	case value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		value.SetBool(b)
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return errors.New("unsupported config field type " + value.Type().String())
+	}
+	return nil
+}
+
+// Print выводит действующие настройки в YAML, секреты скрываются
+func Print(w io.Writer, cfg *Config) error {
+	printed := *cfg
+	walk(&printed, func(field reflect.StructField, value reflect.Value, path string) error {
+		if field.Tag.Get("secret") == "true" && value.String() != "" {
+			value.SetString(redacted)
+		}
+		return nil
+	})
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(&printed)
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Validate проверяет настройки и возвращает все найденные ошибки сразу
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	positive := func(name string, d time.Duration) {
+		check(d > 0, "%s must be positive, got %s", name, d)
+	}
+
+	check(validPort(c.Server.Port), "server.port must be between 1 and 65535, got %d", c.Server.Port)
+	check((c.Server.TLSCertFile == "") == (c.Server.TLSKeyFile == ""),
+		"server.tls_cert_file and server.tls_key_file must be set together")
+	positive("server.read_timeout", c.Server.ReadTimeout)
+	positive("server.write_timeout", c.Server.WriteTimeout)
+	positive("server.idle_timeout", c.Server.IdleTimeout)
+	positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	positive("server.health_timeout", c.Server.HealthTimeout)
+
+	check(c.Database.Host != "", "database.host is required")
+	check(validPort(c.Database.Port), "database.port must be between 1 and 65535, got %d", c.Database.Port)
+	check(c.Database.User != "", "database.user is required")
+	check(c.Database.Name != "", "database.name is required")
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.max_idle_conns must not exceed database.max_open_conns")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time must not be negative")
+	positive("database.idempotency_ttl", c.Database.IdempotencyTTL)
+	check(oneOf(c.Database.SSLMode, "disable", "require", "verify-ca", "verify-full"),
+		"database.ssl_mode must be disable, require, verify-ca or verify-full, got %q", c.Database.SSLMode)
+	check(c.Database.SSLRootCert == "" || c.Database.SSLMode == "verify-ca" || c.Database.SSLMode == "verify-full",
+		"database.ssl_root_cert requires ssl_mode verify-ca or verify-full")
+	check(c.Database.ReplicaMaxLag >= 0, "database.replica_max_lag must not be negative")
+	positive("database.query_timeout", c.Database.QueryTimeout)
+	check(c.Database.ConnectRetries >= 0, "database.connect_retries must not be negative")
+	check(c.Database.BreakerThreshold > 0, "database.breaker_threshold must be positive, got %d", c.Database.BreakerThreshold)
+	positive("database.breaker_cooldown", c.Database.BreakerCooldown)
+
+	check(c.Cache.Host != "", "cache.host is required")
+	check(validPort(c.Cache.Port), "cache.port must be between 1 and 65535, got %d", c.Cache.Port)
+	positive("cache.ttl", c.Cache.TTL)
+	check(c.Cache.PoolSize >= 0, "cache.pool_size must not be negative")
+	positive("cache.dial_timeout", c.Cache.DialTimeout)
+	positive("cache.read_timeout", c.Cache.ReadTimeout)
+	positive("cache.write_timeout", c.Cache.WriteTimeout)
+	check(c.Cache.MaxRetries >= 0, "cache.max_retries must not be negative")
+	check(c.Cache.BreakerThreshold > 0, "cache.breaker_threshold must be positive, got %d", c.Cache.BreakerThreshold)
+	positive("cache.breaker_cooldown", c.Cache.BreakerCooldown)
+
+	check(c.Auth.HS256Secret != "" || c.Auth.RS256PublicKey != "" || c.Auth.JWKSFile != "",
+		"one of auth.hs256_secret, auth.rs256_public_key or auth.jwks_file is required")
+	check(c.Auth.Leeway >= 0, "auth.leeway must not be negative")
+	positive("api_keys.cache_ttl", c.APIKeys.CacheTTL)
+
+	check(oneOf(c.RateLimit.Backend, "memory", "redis"),
+		"rate_limit.backend must be memory or redis, got %q", c.RateLimit.Backend)
+	check(oneOf(c.Tracing.Exporter, "none", "otlp", "stdout", "file"),
+		"tracing.exporter must be none, otlp, stdout or file, got %q", c.Tracing.Exporter)
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Logging.Level)) == nil,
+		"logging.level must be debug, info, warn or error, got %q", c.Logging.Level)
+	positive("preview.max_ttl", c.Preview.MaxTTL)
+	check(!c.Features.Preview || c.Preview.Secret != "", "preview.secret is required when features.preview is enabled")
+
+	return errors.Join(errs...)
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"my_app/internal/apperr"
+	"my_app/internal/models"
+	"time"
+
+	pq "github.com/lib/pq"
+)
+
+var ErrAPIKeyNotFound = apperr.New(apperr.ErrNotFound, "api key not found")
+
+const apiKeyColumns = `id, tenant_id, name, prefix, role, scopes, key_hash, created_at, expires_at, last_used_at, revoked_at`
+
+func createAPIKeysTable() {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS api_keys (
+		id SERIAL PRIMARY KEY,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		role TEXT NOT NULL,
+		scopes TEXT[] NOT NULL DEFAULT '{}',
+		key_hash TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMPTZ,
+		last_used_at TIMESTAMPTZ,
+		revoked_at TIMESTAMPTZ
+	)`)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&key.ID, &key.Tenant, &key.Name, &key.Prefix, &key.Role, pq.Array(&key.Scopes), &key.Hash,
+		&key.CreatedAt, &expiresAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+	return &key, nil
+}
+
+func CreateAPIKey(ctx context.Context, tenant string, request models.APIKeyRequest, prefix string, hash string, actor string) (*models.APIKey, error) {
+	ctx, done := startQuery(ctx, "create_api_key")
+	defer done()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO api_keys (tenant_id, name, prefix, role, scopes, key_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING ` + apiKeyColumns
+	key, err := scanAPIKey(tx.QueryRowContext(ctx, query, tenant, request.Name, prefix, request.Role, pq.Array(request.Scopes), hash, request.ExpiresAt))
+	if err != nil {
+		return nil, err
+	}
+	// Хэш не сериализуется в JSON, поэтому в аудит попадают только данные ключа
+	err = insertAPIKeyAudit(ctx, tx, tenant, actor, models.AuditAPIKeyCreate, key.ID, nil, key)
+	if err != nil {
+		return nil, err
+	}
+	return key, tx.Commit()
+}
+
+func GetAPIKeys(ctx context.Context, tenant string) ([]models.APIKey, error) {
+	ctx, done := startQuery(ctx, "get_api_keys")
+	defer done()
+	keys := []models.APIKey{}
+	rows, err := db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE tenant_id = $1 ORDER BY id`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+func GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	ctx, done := startQuery(ctx, "get_api_key_by_hash")
+	defer done()
+	key, err := scanAPIKey(db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash))
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, err
+}
+
+// RotateAPIKey заменяет секрет действующего ключа, старый секрет сразу перестает работать
+func RotateAPIKey(ctx context.Context, tenant string, id int, prefix string, hash string, actor string) (*models.APIKey, string, error) {
+	ctx, done := startQuery(ctx, "rotate_api_key")
+	defer done()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL FOR UPDATE`
+	before, err := scanAPIKey(tx.QueryRowContext(ctx, query, id, tenant))
+	if err == sql.ErrNoRows {
+		return nil, "", ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	query = `UPDATE api_keys SET prefix = $1, key_hash = $2, last_used_at = NULL
+		WHERE id = $3 RETURNING ` + apiKeyColumns
+	key, err := scanAPIKey(tx.QueryRowContext(ctx, query, prefix, hash, id))
+	if err != nil {
+		return nil, "", err
+	}
+	err = insertAPIKeyAudit(ctx, tx, tenant, actor, models.AuditAPIKeyRotate, key.ID, before, key)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, before.Hash, tx.Commit()
+}
+
+func RevokeAPIKey(ctx context.Context, tenant string, id int, actor string) (string, error) {
+	ctx, done := startQuery(ctx, "revoke_api_key")
+	defer done()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL RETURNING ` + apiKeyColumns
+	key, err := scanAPIKey(tx.QueryRowContext(ctx, query, id, tenant))
+	if err == sql.ErrNoRows {
+		return "", ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	before := *key
+	before.RevokedAt = nil
+	err = insertAPIKeyAudit(ctx, tx, tenant, actor, models.AuditAPIKeyRevoke, key.ID, &before, key)
+	if err != nil {
+		return "", err
+	}
+	return key.Hash, tx.Commit()
+}
+
+// APIKeyStore дает пакету apikey доступ к таблице api_keys
+type APIKeyStore struct{}
+
+func (APIKeyStore) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	return GetAPIKeyByHash(ctx, hash)
+}
+
+func (APIKeyStore) TouchAPIKey(ctx context.Context, id int32, usedAt time.Time) error {
+	return TouchAPIKey(ctx, id, usedAt)
+}
+
+func TouchAPIKey(ctx context.Context, id int32, usedAt time.Time) error {
+	ctx, done := startQuery(ctx, "touch_api_key")
+	defer done()
+	_, err := db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, usedAt, id)
+	return err
+}
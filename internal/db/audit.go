@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"my_app/internal/models"
+)
+
+func createAuditLogTable() {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS audit_log (
+		id BIGSERIAL PRIMARY KEY,
+		actor TEXT NOT NULL,
+		action TEXT NOT NULL,
+		banner_id INT,
+		before JSON,
+		after JSON,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Записи об API ключах ссылаются на ключ, banner_id у них пустой
+	_, err = db.Exec(`ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS api_key_id INT`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, column := range []string{"actor", "banner_id", "api_key_id", "created_at"} {
+		_, err = db.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS audit_log_%s_idx ON audit_log (%s)`, column, column))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// insertAudit пишет запись аудита баннера в той же транзакции, что и само изменение
+func insertAudit(ctx context.Context, tx *sql.Tx, tenant string, actor string, action models.AuditAction, bannerId int32, before interface{}, after interface{}) error {
+	return insertAuditRecord(ctx, tx, tenant, actor, action, "banner_id", bannerId, before, after)
+}
+
+// insertAPIKeyAudit пишет запись аудита API ключа, before и after не должны содержать секрет и хэш
+func insertAPIKeyAudit(ctx context.Context, tx *sql.Tx, tenant string, actor string, action models.AuditAction, keyId int32, before interface{}, after interface{}) error {
+	return insertAuditRecord(ctx, tx, tenant, actor, action, "api_key_id", keyId, before, after)
+}
+
+func insertAuditRecord(ctx context.Context, tx *sql.Tx, tenant string, actor string, action models.AuditAction, column string, id int32, before interface{}, after interface{}) error {
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`INSERT INTO audit_log (tenant_id, actor, action, %s, before, after) VALUES ($1, $2, $3, $4, $5, $6)`, column)
+	_, err = tx.ExecContext(ctx, query, tenant, actor, action, id, beforeJSON, afterJSON)
+	return err
+}
+
+func auditJSON(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func GetAuditLog(ctx context.Context, tenant string, filter models.AuditFilter) ([]models.AuditRecord, error) {
+	ctx, done := startQuery(ctx, "get_audit_log")
+	defer done()
+	records := []models.AuditRecord{}
+	args := []interface{}{tenant}
+	query := `SELECT id, actor, action, banner_id, api_key_id, before, after, created_at FROM audit_log WHERE tenant_id = $1`
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		query += fmt.Sprintf(" AND actor = $%d", len(args))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		query += fmt.Sprintf(" AND action = $%d", len(args))
+	}
+	if filter.BannerId != nil {
+		args = append(args, *filter.BannerId)
+		query += fmt.Sprintf(" AND banner_id = $%d", len(args))
+	}
+	if filter.APIKeyId != nil {
+		args = append(args, *filter.APIKeyId)
+		query += fmt.Sprintf(" AND api_key_id = $%d", len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var record models.AuditRecord
+		var bannerId, apiKeyId sql.NullInt32
+		var before, after []byte
+		err := rows.Scan(&record.ID, &record.Actor, &record.Action, &bannerId, &apiKeyId, &before, &after, &record.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if bannerId.Valid {
+			record.BannerId = &bannerId.Int32
+		}
+		if apiKeyId.Valid {
+			record.APIKeyId = &apiKeyId.Int32
+		}
+		if before != nil {
+			record.Before = before
+		}
+		if after != nil {
+			record.After = after
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"my_app/internal/breaker"
+	"net"
+	"time"
+
+	pq "github.com/lib/pq"
+)
+
+// Пауза перед повторным подключением, растет с каждой попыткой
+const connectBackoff = 50 * time.Millisecond
+
+// dbBreaker создается в InitDB с порогом и паузой из настроек
+var dbBreaker *breaker.Breaker
+
+// Available сообщает, что основная база подключена и не отклоняется автоматом
+func Available() bool {
+	return dbBreaker != nil && dbBreaker.State() != breaker.Open
+}
+
+// breakerConnector пропускает подключения и запросы пула через автомат, пока он разомкнут,
+// запросы сразу завершаются с breaker.ErrOpen, не дожидаясь таймаутов
+type breakerConnector struct {
+	driver.Connector
+	breaker *breaker.Breaker
+	retries int
+}
+
+func (c *breakerConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	var conn driver.Conn
+	err := breaker.Retry(ctx, c.retries, connectBackoff, isFailure, func() error {
+		var err error
+		conn, err = c.Connector.Connect(ctx)
+		return err
+	})
+	c.breaker.Record(isFailure(err))
+	if err != nil {
+		return nil, err
+	}
+	return &breakerConn{conn: conn, breaker: c.breaker}, nil
+}
+
+// breakerConn реализует те же интерфейсы driver, что и соединение lib/pq
+type breakerConn struct {
+	conn    driver.Conn
+	breaker *breaker.Breaker
+}
+
+// call учитывает результат в автомате. Запрос, отмененный клиентом, ничего не говорит
+// о состоянии базы, а отмененный по таймауту - считается ошибкой
+func (c *breakerConn) call(ctx context.Context, call func() error) error {
+	if err := c.breaker.Allow(); err != nil {
+		return err
+	}
+	err := call()
+	c.breaker.Record(isFailure(err) && !errors.Is(ctx.Err(), context.Canceled))
+	return err
+}
+
+func (c *breakerConn) Prepare(query string) (driver.Stmt, error) {
+	return c.conn.Prepare(query)
+}
+
+func (c *breakerConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *breakerConn) Begin() (driver.Tx, error) {
+	return c.conn.Begin()
+}
+
+func (c *breakerConn) PrepareContext(ctx context.Context, query string) (stmt driver.Stmt, err error) {
+	err = c.call(ctx, func() error {
+		stmt, err = c.conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+		return err
+	})
+	return stmt, err
+}
+
+func (c *breakerConn) BeginTx(ctx context.Context, opts driver.TxOptions) (tx driver.Tx, err error) {
+	err = c.call(ctx, func() error {
+		tx, err = c.conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+		return err
+	})
+	return tx, err
+}
+
+func (c *breakerConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	err = c.call(ctx, func() error {
+		rows, err = c.conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+		return err
+	})
+	return rows, err
+}
+
+func (c *breakerConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (result driver.Result, err error) {
+	err = c.call(ctx, func() error {
+		result, err = c.conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+		return err
+	})
+	return result, err
+}
+
+func (c *breakerConn) Ping(ctx context.Context) error {
+	return c.call(ctx, func() error {
+		return c.conn.(driver.Pinger).Ping(ctx)
+	})
+}
+
+func (c *breakerConn) ResetSession(ctx context.Context) error {
+	return c.conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+func (c *breakerConn) IsValid() bool {
+	return c.conn.(driver.Validator).IsValid()
+}
+
+// isFailure отделяет недоступность базы от ошибок самих запросов, например нарушения ограничений
+func isFailure(err error) bool {
+	var pqErr *pq.Error
+	var netErr net.Error
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return false
+	case errors.As(err, &pqErr):
+		// Классы ошибок подключения, нехватки ресурсов, остановки сервера и системных ошибок
+		switch pqErr.Code.Class() {
+		case "08", "53", "57", "58":
+			return true
+		}
+		return false
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &netErr):
+		return true
+	}
+	return false
+}
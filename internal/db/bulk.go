@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"my_app/internal/models"
+)
+
+func ExportBanners(ctx context.Context, tenant string, featureId *int, tagId *int, fn func(models.BannerExpanded) error) error {
+	ctx, done := startLongQuery(ctx, "export_banners")
+	defer done()
+	query := `SELECT ` + bannerColumns + ` FROM banners WHERE tenant_id = $1`
+	if featureId != nil {
+		query += fmt.Sprintf(" AND feature_id = %d", *featureId)
+	}
+	if tagId != nil {
+		query += fmt.Sprintf(" AND %d = ANY(tag_ids)", *tagId)
+	}
+	query += " ORDER BY id"
+
+	rows, err := db.QueryContext(ctx, query, tenant)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		banner, err := scanBanner(rows)
+		if err != nil {
+			return err
+		}
+		err = fn(*banner)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ImportBanners записывает баннеры в одной транзакции, в ошибке указывается номер строки входных данных
+func ImportBanners(ctx context.Context, tenant string, banners []models.ImportedBanner, actor string) ([]int32, error) {
+	ctx, done := startLongQuery(ctx, "import_banners")
+	defer done()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int32, 0, len(banners))
+	for _, banner := range banners {
+		bannerId, err := insertBannerDraft(ctx, tx, tenant, banner.Banner, actor)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", banner.Row, err)
+		}
+		err = insertAudit(ctx, tx, tenant, actor, models.AuditImport, bannerId, nil, banner.Banner)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", banner.Row, err)
+		}
+		ids = append(ids, bannerId)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -1,42 +1,96 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"my_app/internal/background"
+	"my_app/internal/breaker"
 	"my_app/internal/cache"
+	"my_app/internal/config"
+	"my_app/internal/locale"
+	"my_app/internal/metrics"
 	"my_app/internal/models"
-	"os"
-	"strings"
+	"my_app/internal/templating"
+	"my_app/internal/tracing"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 
 	pq "github.com/lib/pq" // PostgreSQL driver
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var db *sql.DB
 
-func InitDB() {
-	var err error
-	param := fmt.Sprintf("postgres://%v:%v@%v:%v/%v?sslmode=disable",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-	)
-	db, err = sql.Open("postgres", param)
-	if err != nil {
-		log.Fatal(err)
+// Останавливает периодические задачи базы данных, заменяется в InitDB
+var stopWorkers context.CancelFunc = func() {}
+
+// Таймаут одного запроса, задается в InitDB
+var queryTimeout = 5 * time.Second
+
+func InitDB(cfg config.Database) {
+	query := url.Values{"sslmode": {cfg.SSLMode}}
+	if cfg.SSLMode == "" {
+		query.Set("sslmode", "disable")
+	}
+	if cfg.SSLRootCert != "" {
+		query.Set("sslrootcert", cfg.SSLRootCert)
 	}
+	param := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     cfg.Name,
+		RawQuery: query.Encode(),
+	}
+	dbBreaker = breaker.New("postgres", cfg.BreakerThreshold, cfg.BreakerCooldown)
+	db = openPool(param.String(), cfg, "banners", dbBreaker)
+	initReplica(cfg)
+	idempotencyTTL = cfg.IdempotencyTTL
+	queryTimeout = cfg.QueryTimeout
 
 	createBannersTable()
+	createWorkflowTables()
+	createFeatureSchemasTable()
+	createAuditLogTable()
+	createAPIKeysTable()
+	initIdempotency()
+	createTenantColumns()
+	migrated.Store(true)
+
+	stopWorkers()
+	var workers context.Context
+	workers, stopWorkers = context.WithCancel(context.Background())
+	background.Every(workers, idempotencyCleanupInterval, cleanupIdempotencyKeys)
+	startReplicaCheck(workers)
 }
 
-func CloseDB() {
-	err := db.Close()
+// openPool открывает пул соединений с настройками размера и времени жизни из cfg,
+// подключения и запросы пула проходят через автомат b
+func openPool(dsn string, cfg config.Database, name string, b *breaker.Breaker) *sql.DB {
+	connector, err := pq.NewConnector(dsn)
 	if err != nil {
-		log.Fatalf("Error closing PostgreSQL connection: %v", err)
+		log.Fatal(err)
 	}
+	pool := sql.OpenDB(&breakerConnector{Connector: connector, breaker: b, retries: cfg.ConnectRetries})
+	pool.SetMaxOpenConns(cfg.MaxOpenConns)
+	pool.SetMaxIdleConns(cfg.MaxIdleConns)
+	pool.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	pool.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	metrics.RegisterDBStats(pool, name)
+	return pool
+}
+
+func CloseDB() error {
+	stopWorkers()
+	return errors.Join(closeReplica(), db.Close())
 }
 
 func createBannersTable() {
@@ -54,31 +108,63 @@ func createBannersTable() {
 	}
 }
 
-func GetBannerForUser(featureId *int, tagId *int, use_last_revision bool, isAdmin bool) (*models.ModelMap, error) {
-	var banner *models.BannerExpanded
+// GetBannerForUser читает баннер из кэша, а при промахе или use_last_revision - из базы.
+// Пока Redis недоступен, кэш пропускается. Пока недоступна база, use_last_revision
+// не соблюдается и баннер отдается из кэша, если он там есть
+func GetBannerForUser(ctx context.Context, tenant string, featureId *int, tagId *int, locales []string, use_last_revision bool, isAdmin bool) (*models.LocalizedBanner, error) {
+	var banner *models.LocalizedBanner
 	var err error
-	if !use_last_revision {
-		banner, err = cache.GetBannerFromCache(featureId, tagId)
+	readCache := cache.Available() && (!use_last_revision || !Available())
+	if readCache {
+		banner, err = cache.GetBannerFromCache(ctx, tenant, featureId, tagId, locales)
+		if err != nil && !errors.Is(err, cache.ErrMiss) {
+			slog.WarnContext(ctx, "cache is unavailable, reading from database", "error", err)
+		}
+		if err == nil && use_last_revision {
+			slog.WarnContext(ctx, "database is unavailable, serving banner from cache")
+		}
 	}
-	if use_last_revision || err != nil && strings.Contains(err.Error(), "no banner found") {
-		banner, err = getBannerFromDB(featureId, tagId)
+	if !readCache || err != nil {
+		var dbBanner *models.BannerExpanded
+		dbBanner, err = getBannerFromDB(ctx, tenant, featureId, tagId, use_last_revision)
 		if err != nil {
 			return nil, err
 		}
-		cache.SaveBannerToCacheAsync(featureId, tagId, banner)
-	} else if err != nil {
-		return nil, err
+		var ok bool
+		banner, ok = locale.Localize(dbBanner, locales)
+		if !ok {
+			return nil, ErrNoLocale
+		}
+		// В кэш попадают разобранные шаблоны, а не результат подстановки
+		ParseTemplates(ctx, banner)
+		if cache.Available() {
+			cache.SaveBannerToCacheAsync(ctx, tenant, featureId, tagId, banner)
+		}
 	}
 	if !banner.IsActive && !isAdmin {
-		return nil, fmt.Errorf("no banner found")
+		return nil, ErrBannerNotFound
 	}
 
-	return &banner.Content, nil
+	return banner, nil
 }
 
-func getBannerFromDB(featureId *int, tagId *int) (*models.BannerExpanded, error) {
-	var banner models.BannerExpanded
-	query := `SELECT * FROM banners WHERE 1=1`
+// ParseTemplates разбирает шаблоны content сохраненного баннера. Ошибки появляются, если баннер
+// сохранен до изменения TEMPLATE_VARS: такие строки отдаются без подстановки, а ошибки пишутся в лог
+func ParseTemplates(ctx context.Context, banner *models.LocalizedBanner) {
+	var fields []models.FieldError
+	banner.Templates, fields = templating.ParseContent(banner.Content)
+	for _, field := range fields {
+		slog.WarnContext(ctx, "banner content contains invalid template",
+			"banner_id", banner.ID, "field", field.Field, "error", field.Error)
+	}
+}
+
+// getBannerFromDB читает с реплики, свежие данные (fresh) - с основной базы, если реплика отстает
+func getBannerFromDB(ctx context.Context, tenant string, featureId *int, tagId *int, fresh bool) (*models.BannerExpanded, error) {
+	ctx, done := startQuery(ctx, "get_user_banner")
+	defer done()
+	// Пользователям видны только баннеры с опубликованной ревизией
+	query := `SELECT ` + bannerColumns + ` FROM banners WHERE tenant_id = $1 AND published_revision IS NOT NULL`
 	if featureId != nil {
 		query += fmt.Sprintf(" AND feature_id = %d", *featureId)
 	}
@@ -87,27 +173,22 @@ func getBannerFromDB(featureId *int, tagId *int) (*models.BannerExpanded, error)
 	}
 	query += " LIMIT 1"
 
-	row := db.QueryRow(query)
-	var contentJSON []byte
-	err := row.Scan(&banner.ID, pq.Array(&banner.TagIds), &banner.FeatureId, &contentJSON, &banner.IsActive, &banner.CreatedAt, &banner.UpdatedAt)
+	banner, err := scanBanner(readDB(ctx, fresh).QueryRowContext(ctx, query, tenant))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("no banner found")
+		return nil, ErrBannerNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	err = json.Unmarshal(contentJSON, &banner.Content)
-	if err != nil {
-		return nil, err
-	}
-	return &banner, nil
+	return banner, nil
 }
 
-func GetBanners(featureId *int, tagId *int, limit *int, offset *int) ([]models.BannerExpanded, error) {
+func GetBanners(ctx context.Context, tenant string, featureId *int, tagId *int, limit *int, offset *int) ([]models.BannerExpanded, error) {
+	ctx, done := startQuery(ctx, "get_banners")
+	defer done()
 	var banners []models.BannerExpanded
 
-	query := `SELECT * FROM banners WHERE 1=1`
+	query := `SELECT ` + bannerColumns + ` FROM banners WHERE tenant_id = $1`
 	if featureId != nil {
 		query += fmt.Sprintf(" AND feature_id = %d", *featureId)
 	}
@@ -121,73 +202,165 @@ func GetBanners(featureId *int, tagId *int, limit *int, offset *int) ([]models.B
 		query += fmt.Sprintf(" OFFSET %d", *offset)
 	}
 
-	rows, err := db.Query(query)
+	rows, err := readDB(ctx, false).QueryContext(ctx, query, tenant)
 	if err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var banner models.BannerExpanded
-		var contentJSON []byte
-		err := rows.Scan(&banner.ID, pq.Array(&banner.TagIds), &banner.FeatureId, &contentJSON, &banner.IsActive, &banner.CreatedAt, &banner.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-
-		err = json.Unmarshal(contentJSON, &banner.Content)
+		banner, err := scanBanner(rows)
 		if err != nil {
 			return nil, err
 		}
-		banners = append(banners, banner)
+		banners = append(banners, *banner)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
 
 	return banners, nil
 }
 
-func CreateBanner(banner models.BannerNoId) (int32, error) {
-	contentJSON, err := json.Marshal(banner.Content)
+const bannerColumns = `id, tag_ids, feature_id, content, is_active, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBanner(row rowScanner) (*models.BannerExpanded, error) {
+	var banner models.BannerExpanded
+	var contentJSON []byte
+	err := row.Scan(&banner.ID, pq.Array(&banner.TagIds), &banner.FeatureId, &contentJSON, &banner.IsActive, &banner.CreatedAt, &banner.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(contentJSON, &banner.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &banner, nil
+}
+
+func CreateBanner(ctx context.Context, tenant string, banner models.BannerNoId, actor string) (int32, error) {
+	ctx, done := startQuery(ctx, "create_banner")
+	defer done()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, err
 	}
+	defer tx.Rollback()
 
-	query := `INSERT INTO banners (tag_ids, feature_id, content, is_active) VALUES ($1, $2, $3, $4) RETURNING id`
-	var bannerId int32
-	err = db.QueryRow(query, pq.Array(banner.TagIds), banner.FeatureId, contentJSON, banner.IsActive).Scan(&bannerId)
+	bannerId, err := insertBannerDraft(ctx, tx, tenant, banner, actor)
+	if err != nil {
+		return 0, err
+	}
+	err = insertAudit(ctx, tx, tenant, actor, models.AuditCreate, bannerId, nil, banner)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Commit()
 	if err != nil {
 		return 0, err
 	}
 	return bannerId, nil
 }
 
-func UpdateBanner(id int, banner models.BannerNoId) error {
-	contentJSON, err := json.Marshal(banner.Content)
+func DeleteBanner(ctx context.Context, tenant string, id int, actor string) error {
+	ctx, done := startQuery(ctx, "delete_banner")
+	defer done()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	banner, err := scanBanner(tx.QueryRowContext(ctx, `SELECT `+bannerColumns+` FROM banners WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, id, tenant))
+	if err == sql.ErrNoRows {
+		return ErrBannerNotFound
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM banners WHERE id = $1`, id)
 	if err != nil {
 		return err
 	}
-	query := `UPDATE banners SET tag_ids = $1, feature_id = $2, content = $3, is_active = $4, updated_at = NOW() WHERE id = $5`
-	_, err = db.Exec(query, pq.Array(banner.TagIds), banner.FeatureId, contentJSON, banner.IsActive, id)
-	return err
+	err = insertAudit(ctx, tx, tenant, actor, models.AuditDelete, banner.ID, banner, nil)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func DeleteBanner(id int) error {
-	query := `DELETE FROM banners WHERE id = $1`
-	_, err := db.Exec(query, id)
-	return err
+// GetBannerFeatureIds возвращает фичу баннера и фичи его открытых ревизий,
+// ревизия может переносить баннер в другую фичу
+func GetBannerFeatureIds(ctx context.Context, tenant string, id int) ([]int32, error) {
+	ctx, done := startQuery(ctx, "get_banner_feature_ids")
+	defer done()
+	query := `SELECT feature_id FROM banners WHERE id = $1 AND tenant_id = $2
+		UNION SELECT r.feature_id FROM banner_revisions r JOIN banners b ON b.id = r.banner_id
+		WHERE r.banner_id = $1 AND b.tenant_id = $2 AND r.status NOT IN ('published', 'archived')`
+	rows, err := db.QueryContext(ctx, query, id, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var featureIds []int32
+	for rows.Next() {
+		var featureId int32
+		err = rows.Scan(&featureId)
+		if err != nil {
+			return nil, err
+		}
+		featureIds = append(featureIds, featureId)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(featureIds) == 0 {
+		return nil, ErrBannerNotFound
+	}
+	return featureIds, nil
 }
 
-func BannerExists(id int) (bool, error) {
+func BannerExists(ctx context.Context, tenant string, id int) (bool, error) {
+	ctx, done := startQuery(ctx, "banner_exists")
+	defer done()
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM banners WHERE id = $1)`
-	err := db.QueryRow(query, id).Scan(&exists)
+	query := `SELECT EXISTS(SELECT 1 FROM banners WHERE id = $1 AND tenant_id = $2)`
+	err := db.QueryRowContext(ctx, query, id, tenant).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
 	return exists, nil
 }
+
+var tracer = tracing.Tracer("db")
+
+// startQuery открывает span операции с базой данных и ограничивает ее queryTimeout, done закрывает span,
+// учитывает длительность операции в метриках и пишет ее в отладочный лог
+func startQuery(ctx context.Context, query string) (context.Context, func()) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, done := startLongQuery(ctx, query)
+	return ctx, func() {
+		done()
+		cancel()
+	}
+}
+
+// startLongQuery - startQuery без таймаута для выгрузки и загрузки, их длительность зависит от объема
+func startLongQuery(ctx context.Context, query string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "db."+query,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(query)))
+	return ctx, func() {
+		span.End()
+		duration := time.Since(start)
+		metrics.DBQueryDuration.WithLabelValues(query).Observe(duration.Seconds())
+		slog.DebugContext(ctx, "db query", "query", query, "duration", duration)
+	}
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"my_app/internal/apperr"
+	"sync/atomic"
+
+	pq "github.com/lib/pq"
+)
+
+var (
+	ErrNotInitialized = apperr.New(apperr.ErrUnavailable, "database is not initialized")
+	ErrNotMigrated    = apperr.New(apperr.ErrUnavailable, "migrations are not applied")
+)
+
+// migrated выставляется после миграций в InitDB
+var migrated atomic.Bool
+
+func Ping(ctx context.Context) error {
+	if db == nil {
+		return ErrNotInitialized
+	}
+	return db.PingContext(ctx)
+}
+
+// CheckMigrations проверяет, что миграции этой версии завершены: таблицы
+// созданы и последняя миграция (tenant_id во всех таблицах тенантов) применена
+func CheckMigrations(ctx context.Context) error {
+	if db == nil {
+		return ErrNotInitialized
+	}
+	if !migrated.Load() {
+		return ErrNotMigrated
+	}
+	tables := make([]string, 0, len(tenantTables))
+	for _, t := range tenantTables {
+		tables = append(tables, t.table)
+	}
+	var count int
+	err := db.QueryRowContext(ctx, `SELECT count(*) FROM information_schema.columns
+		WHERE table_schema = current_schema() AND column_name = 'tenant_id' AND table_name = ANY($1)`,
+		pq.Array(tables)).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count != len(tables) {
+		return fmt.Errorf("%w: tenant_id is missing in %d of %d tables", ErrNotMigrated, len(tables)-count, len(tables))
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"my_app/internal/models"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultIdempotencyTTL = 24 * time.Hour
+	// Как часто удалять просроченные ключи, к которым больше не обращаются
+	idempotencyCleanupInterval = 10 * time.Minute
+)
+
+var idempotencyTTL time.Duration
+
+func initIdempotency() {
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = defaultIdempotencyTTL
+	}
+	createIdempotencyKeysTable()
+}
+
+func createIdempotencyKeysTable() {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS idempotency_keys (
+		key TEXT PRIMARY KEY,
+		request_hash TEXT NOT NULL,
+		status_code INT NOT NULL,
+		response JSON NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at ON idempotency_keys (expires_at)`)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// cleanupIdempotencyKeys - периодическая задача, без нее таблица росла бы за счет неповторенных ключей
+func cleanupIdempotencyKeys(ctx context.Context) {
+	deleted, err := DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to delete expired idempotency keys", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.DebugContext(ctx, "expired idempotency keys deleted", "count", deleted)
+	}
+}
+
+// DeleteExpiredIdempotencyKeys удаляет просроченные ключи всех тенантов и возвращает их число
+func DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	ctx, done := startQuery(ctx, "delete_expired_idempotency_keys")
+	defer done()
+	result, err := db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CreateBannerIdempotent создает баннер не более одного раза для ключа.
+// Если ключ уже использовался, возвращается сохраненная запись и created = false,
+// проверка совпадения хэша запроса остается за вызывающей стороной.
+func CreateBannerIdempotent(ctx context.Context, tenant string, key string, requestHash string, banner models.BannerNoId, actor string) (record *models.IdempotencyRecord, created bool, err error) {
+	ctx, done := startQuery(ctx, "create_banner_idempotent")
+	defer done()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Конкурентные запросы с одним ключом выполняются последовательно
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1 || ':' || $2))`, tenant, key)
+	if err != nil {
+		return nil, false, err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE tenant_id = $1 AND key = $2 AND expires_at < NOW()`, tenant, key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	record, err = getIdempotencyRecord(ctx, tx, tenant, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if record != nil {
+		err = tx.Commit()
+		return record, false, err
+	}
+
+	var response models.IdResponse
+	response.BannerId, err = insertBannerDraft(ctx, tx, tenant, banner, actor)
+	if err != nil {
+		return nil, false, err
+	}
+	err = insertAudit(ctx, tx, tenant, actor, models.AuditCreate, response.BannerId, nil, banner)
+	if err != nil {
+		return nil, false, err
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return nil, false, err
+	}
+	record = &models.IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		StatusCode:  http.StatusCreated,
+		Response:    responseJSON,
+	}
+	query := `INSERT INTO idempotency_keys (tenant_id, key, request_hash, status_code, response, expires_at)
+		VALUES ($1, $2, $3, $4, $5, NOW() + $6 * INTERVAL '1 second') RETURNING created_at, expires_at`
+	err = tx.QueryRowContext(ctx, query, tenant, key, requestHash, record.StatusCode, responseJSON, idempotencyTTL.Seconds()).
+		Scan(&record.CreatedAt, &record.ExpiresAt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, false, err
+	}
+	return record, true, nil
+}
+
+func getIdempotencyRecord(ctx context.Context, tx *sql.Tx, tenant string, key string) (*models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+	query := `SELECT key, request_hash, status_code, response, created_at, expires_at FROM idempotency_keys WHERE tenant_id = $1 AND key = $2`
+	err := tx.QueryRowContext(ctx, query, tenant, key).Scan(&record.Key, &record.RequestHash, &record.StatusCode, &record.Response, &record.CreatedAt, &record.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
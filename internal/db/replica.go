@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"my_app/internal/background"
+	"my_app/internal/breaker"
+	"my_app/internal/config"
+	"my_app/internal/metrics"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// Как часто проверять отставание реплики в фоне, запросы используют последний результат
+	replicaCheckInterval = time.Second
+	replicaCheckTimeout  = 500 * time.Millisecond
+)
+
+// Отставание реплики в секундах. Если реплика получила весь WAL, она не отстает,
+// даже если последняя транзакция была давно
+const replicaLagQuery = `SELECT COALESCE(CASE
+		WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END, 0)`
+
+// replicaState - результат последней проверки отставания реплики
+type replicaState struct {
+	lag time.Duration
+	err error
+}
+
+var (
+	replica       *sql.DB
+	replicaMaxLag time.Duration
+	// Обновляется фоновой проверкой, запросы читают его без ожидания
+	replicaStatus atomic.Pointer[replicaState]
+)
+
+// initReplica открывает пул реплики и проверяет ее отставание до первых запросов,
+// дальше проверку раз в replicaCheckInterval выполняет startReplicaCheck
+func initReplica(cfg config.Database) {
+	replica = nil
+	replicaStatus.Store(nil)
+	if cfg.ReplicaDSN == "" {
+		return
+	}
+	replica = openPool(cfg.ReplicaDSN, cfg, "banners_replica",
+		breaker.New("postgres_replica", cfg.BreakerThreshold, cfg.BreakerCooldown))
+	replicaMaxLag = cfg.ReplicaMaxLag
+	checkReplica(context.Background())
+}
+
+func startReplicaCheck(ctx context.Context) {
+	if replica != nil {
+		background.Every(ctx, replicaCheckInterval, checkReplica)
+	}
+}
+
+func closeReplica() error {
+	if replica == nil {
+		return nil
+	}
+	return replica.Close()
+}
+
+// readDB выбирает базу для чтения: реплику, пока она доступна, а для свежих данных
+// (use_last_revision) - только если она отстает не больше replica_max_lag
+func readDB(ctx context.Context, fresh bool) *sql.DB {
+	target, conn := "primary", db
+	if replica != nil {
+		status := replicaStatus.Load()
+		if status != nil && status.err == nil && (!fresh || status.lag <= replicaMaxLag) {
+			target, conn = "replica", replica
+		}
+	}
+	metrics.DBReads.WithLabelValues(target).Inc()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.target", target))
+	return conn
+}
+
+func checkReplica(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+	defer cancel()
+	var seconds float64
+	err := replica.QueryRowContext(checkCtx, replicaLagQuery).Scan(&seconds)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Проверка прервана остановкой, результат не говорит о состоянии реплики
+			return
+		}
+		slog.WarnContext(ctx, "read replica is unavailable, reading from primary", "error", err)
+		replicaStatus.Store(&replicaState{err: err})
+		return
+	}
+	replicaStatus.Store(&replicaState{lag: time.Duration(seconds * float64(time.Second))})
+	metrics.DBReplicaLag.Set(seconds)
+}
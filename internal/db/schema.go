@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"my_app/internal/models"
+)
+
+func createFeatureSchemasTable() {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS feature_schemas (
+		feature_id INT PRIMARY KEY,
+		schema JSON NOT NULL,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func GetFeatureSchema(ctx context.Context, tenant string, featureId int) (*models.FeatureSchema, error) {
+	ctx, done := startQuery(ctx, "get_feature_schema")
+	defer done()
+	var featureSchema models.FeatureSchema
+	query := `SELECT feature_id, schema, updated_at FROM feature_schemas WHERE tenant_id = $1 AND feature_id = $2`
+	err := db.QueryRowContext(ctx, query, tenant, featureId).Scan(&featureSchema.FeatureId, &featureSchema.Schema, &featureSchema.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &featureSchema, nil
+}
+
+func SetFeatureSchema(ctx context.Context, tenant string, featureId int, schema []byte) error {
+	ctx, done := startQuery(ctx, "set_feature_schema")
+	defer done()
+	query := `INSERT INTO feature_schemas (tenant_id, feature_id, schema) VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, feature_id) DO UPDATE SET schema = EXCLUDED.schema, updated_at = NOW()`
+	_, err := db.ExecContext(ctx, query, tenant, featureId, schema)
+	return err
+}
+
+func DeleteFeatureSchema(ctx context.Context, tenant string, featureId int) (bool, error) {
+	ctx, done := startQuery(ctx, "delete_feature_schema")
+	defer done()
+	result, err := db.ExecContext(ctx, `DELETE FROM feature_schemas WHERE tenant_id = $1 AND feature_id = $2`, tenant, featureId)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// addTenantColumn добавляет в таблицу tenant_id, существующие строки остаются в тенанте по умолчанию.
+// Если задан primaryKey, первичный ключ пересоздается вместе с tenant_id,
+// чтобы одинаковые ключи разных тенантов не конфликтовали
+func addTenantColumn(table string, primaryKey ...string) {
+	migration := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';`, table)
+	if len(primaryKey) > 0 {
+		migration += fmt.Sprintf(` ALTER TABLE %s DROP CONSTRAINT %s_pkey; ALTER TABLE %s ADD PRIMARY KEY (tenant_id, %s);`,
+			table, table, table, strings.Join(primaryKey, ", "))
+	}
+	_, err := db.Exec(fmt.Sprintf(`DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = '%s' AND column_name = 'tenant_id') THEN
+			%s
+		END IF;
+	END $$`, table, migration))
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = db.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_tenant_id_idx ON %s (tenant_id)`, table, table))
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// tenantTables - таблицы с данными тенантов и их первичные ключи без tenant_id
+var tenantTables = []struct {
+	table      string
+	primaryKey []string
+}{
+	{"banners", nil},
+	{"feature_schemas", []string{"feature_id"}},
+	{"audit_log", nil},
+	{"api_keys", nil},
+	{"idempotency_keys", []string{"key"}},
+}
+
+func createTenantColumns() {
+	for _, t := range tenantTables {
+		addTenantColumn(t.table, t.primaryKey...)
+	}
+}
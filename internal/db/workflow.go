@@ -0,0 +1,381 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"my_app/internal/apperr"
+	"my_app/internal/models"
+
+	pq "github.com/lib/pq"
+)
+
+var (
+	ErrRevisionLocked    = apperr.New(apperr.ErrConflict, "banner revision is under review and cannot be edited")
+	ErrInvalidTransition = apperr.New(apperr.ErrConflict, "transition is not allowed from current status")
+	ErrNoOpenRevision    = apperr.New(apperr.ErrConflict, "banner has no revision in progress")
+	ErrUnknownTransition = apperr.New(apperr.ErrValidation, "unknown transition action")
+	ErrBannerNotFound    = apperr.New(apperr.ErrNotFound, "no banner found")
+	// Баннер есть, но ни одна его локаль не подходит запросу
+	ErrNoLocale = apperr.New(apperr.ErrNotFound, "no banner found for requested locale")
+)
+
+type transitionRule struct {
+	from []models.RevisionStatus
+	to   models.RevisionStatus
+}
+
+var transitionRules = map[models.TransitionAction]transitionRule{
+	models.ActionSubmit:   {from: []models.RevisionStatus{models.RevisionDraft}, to: models.RevisionInReview},
+	models.ActionWithdraw: {from: []models.RevisionStatus{models.RevisionInReview}, to: models.RevisionDraft},
+	models.ActionApprove:  {from: []models.RevisionStatus{models.RevisionInReview}, to: models.RevisionApproved},
+	models.ActionReject:   {from: []models.RevisionStatus{models.RevisionInReview, models.RevisionApproved}, to: models.RevisionDraft},
+	models.ActionPublish:  {from: []models.RevisionStatus{models.RevisionApproved}, to: models.RevisionPublished},
+}
+
+const revisionColumns = `id, banner_id, revision, tag_ids, feature_id, content, is_active, status, created_by, created_at, updated_at`
+
+func createWorkflowTables() {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS banner_revisions (
+		id SERIAL PRIMARY KEY,
+		banner_id INT NOT NULL REFERENCES banners (id) ON DELETE CASCADE,
+		revision INT NOT NULL,
+		tag_ids INT[] NOT NULL,
+		feature_id INT NOT NULL,
+		content JSON NOT NULL,
+		is_active BOOLEAN NOT NULL,
+		status TEXT NOT NULL,
+		created_by TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (banner_id, revision)
+	)`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS banner_transitions (
+		id SERIAL PRIMARY KEY,
+		banner_id INT NOT NULL REFERENCES banners (id) ON DELETE CASCADE,
+		revision_id INT NOT NULL REFERENCES banner_revisions (id) ON DELETE CASCADE,
+		action TEXT NOT NULL,
+		from_status TEXT NOT NULL,
+		to_status TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		comment TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = db.Exec(`ALTER TABLE banners ADD COLUMN IF NOT EXISTS published_revision INT`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Баннеры, созданные до появления ревизий, считаются опубликованными
+	_, err = db.Exec(`INSERT INTO banner_revisions (banner_id, revision, tag_ids, feature_id, content, is_active, status, created_by, created_at, updated_at)
+		SELECT id, 1, tag_ids, feature_id, content, is_active, 'published', 'migration', created_at, updated_at
+		FROM banners b WHERE NOT EXISTS (SELECT 1 FROM banner_revisions r WHERE r.banner_id = b.id)`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = db.Exec(`UPDATE banners b SET published_revision = r.id FROM banner_revisions r
+		WHERE r.banner_id = b.id AND r.status = 'published' AND b.published_revision IS NULL`)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// insertBannerDraft создает баннер без опубликованной ревизии и его первый черновик
+func insertBannerDraft(ctx context.Context, tx *sql.Tx, tenant string, banner models.BannerNoId, actor string) (int32, error) {
+	contentJSON, err := json.Marshal(banner.Content)
+	if err != nil {
+		return 0, err
+	}
+	var bannerId int32
+	query := `INSERT INTO banners (tenant_id, tag_ids, feature_id, content, is_active) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err = tx.QueryRowContext(ctx, query, tenant, pq.Array(banner.TagIds), banner.FeatureId, contentJSON, banner.IsActive).Scan(&bannerId)
+	if err != nil {
+		return 0, err
+	}
+	_, err = insertRevision(ctx, tx, bannerId, 1, banner, actor)
+	if err != nil {
+		return 0, err
+	}
+	return bannerId, nil
+}
+
+func insertRevision(ctx context.Context, tx *sql.Tx, bannerId int32, revision int32, banner models.BannerNoId, actor string) (int32, error) {
+	contentJSON, err := json.Marshal(banner.Content)
+	if err != nil {
+		return 0, err
+	}
+	var revisionId int32
+	query := `INSERT INTO banner_revisions (banner_id, revision, tag_ids, feature_id, content, is_active, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	err = tx.QueryRowContext(ctx, query, bannerId, revision, pq.Array(banner.TagIds), banner.FeatureId, contentJSON, banner.IsActive, models.RevisionDraft, actor).
+		Scan(&revisionId)
+	if err != nil {
+		return 0, err
+	}
+	err = insertTransition(ctx, tx, bannerId, revisionId, models.ActionCreate, "", models.RevisionDraft, actor, "")
+	if err != nil {
+		return 0, err
+	}
+	return revisionId, nil
+}
+
+func insertTransition(ctx context.Context, tx *sql.Tx, bannerId int32, revisionId int32, action models.TransitionAction, from models.RevisionStatus, to models.RevisionStatus, actor string, comment string) error {
+	query := `INSERT INTO banner_transitions (banner_id, revision_id, action, from_status, to_status, actor, comment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := tx.ExecContext(ctx, query, bannerId, revisionId, action, from, to, actor, comment)
+	return err
+}
+
+func scanRevision(row rowScanner) (*models.BannerRevision, error) {
+	var revision models.BannerRevision
+	var contentJSON []byte
+	err := row.Scan(&revision.ID, &revision.BannerId, &revision.Revision, pq.Array(&revision.TagIds), &revision.FeatureId,
+		&contentJSON, &revision.IsActive, &revision.Status, &revision.CreatedBy, &revision.CreatedAt, &revision.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(contentJSON, &revision.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// lockOpenRevision блокирует баннер тенанта и возвращает его незавершенную ревизию, если она есть
+func lockOpenRevision(ctx context.Context, tx *sql.Tx, tenant string, bannerId int) (*models.BannerRevision, error) {
+	var id int32
+	err := tx.QueryRowContext(ctx, `SELECT id FROM banners WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, bannerId, tenant).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, ErrBannerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + revisionColumns + ` FROM banner_revisions WHERE banner_id = $1 AND status IN ($2, $3, $4)`
+	revision, err := scanRevision(tx.QueryRowContext(ctx, query, bannerId, models.RevisionDraft, models.RevisionInReview, models.RevisionApproved))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return revision, err
+}
+
+func UpdateBanner(ctx context.Context, tenant string, id int, banner models.BannerNoId, actor string) error {
+	ctx, done := startQuery(ctx, "update_banner")
+	defer done()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	revision, err := lockOpenRevision(ctx, tx, tenant, id)
+	if err != nil {
+		return err
+	}
+	// До изменения - черновик, если он есть, иначе опубликованный баннер
+	var before interface{}
+	if revision != nil {
+		before = revisionBanner(revision)
+	} else {
+		before, err = scanBanner(tx.QueryRowContext(ctx, `SELECT `+bannerColumns+` FROM banners WHERE id = $1`, id))
+		if err != nil {
+			return err
+		}
+	}
+	switch {
+	case revision == nil:
+		// Правка опубликованного баннера начинается с нового черновика
+		var next int32
+		err = tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(revision), 0) + 1 FROM banner_revisions WHERE banner_id = $1`, id).Scan(&next)
+		if err != nil {
+			return err
+		}
+		_, err = insertRevision(ctx, tx, int32(id), next, banner, actor)
+		if err != nil {
+			return err
+		}
+	case revision.Status == models.RevisionDraft:
+		contentJSON, err := json.Marshal(banner.Content)
+		if err != nil {
+			return err
+		}
+		query := `UPDATE banner_revisions SET tag_ids = $1, feature_id = $2, content = $3, is_active = $4, updated_at = NOW() WHERE id = $5`
+		_, err = tx.ExecContext(ctx, query, pq.Array(banner.TagIds), banner.FeatureId, contentJSON, banner.IsActive, revision.ID)
+		if err != nil {
+			return err
+		}
+	default:
+		return ErrRevisionLocked
+	}
+	err = insertAudit(ctx, tx, tenant, actor, models.AuditUpdate, int32(id), before, banner)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func revisionBanner(revision *models.BannerRevision) models.BannerNoId {
+	return models.BannerNoId{
+		TagIds:    revision.TagIds,
+		FeatureId: revision.FeatureId,
+		Content:   revision.Content,
+		IsActive:  revision.IsActive,
+	}
+}
+
+func TransitionBanner(ctx context.Context, tenant string, id int, action models.TransitionAction, actor string, comment string) (*models.BannerRevision, error) {
+	ctx, done := startQuery(ctx, "transition_banner")
+	defer done()
+	rule, ok := transitionRules[action]
+	if !ok {
+		return nil, ErrUnknownTransition
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	revision, err := lockOpenRevision(ctx, tx, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, ErrNoOpenRevision
+	}
+	allowed := false
+	for _, status := range rule.from {
+		allowed = allowed || revision.Status == status
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: %s from %s", ErrInvalidTransition, action, revision.Status)
+	}
+
+	before := map[string]interface{}{"revision": revision.Revision, "status": revision.Status}
+	after := map[string]interface{}{"revision": revision.Revision, "status": rule.to}
+	if rule.to == models.RevisionPublished {
+		// Для публикации в аудит попадает то, что видели пользователи, и то, что увидят
+		published, err := scanBanner(tx.QueryRowContext(ctx, `SELECT `+bannerColumns+` FROM banners WHERE id = $1 AND published_revision IS NOT NULL`, id))
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if published != nil {
+			before["banner"] = published
+		}
+		after["banner"] = revisionBanner(revision)
+
+		// Опубликованное содержимое переносится в banners, откуда его читает /user_banner
+		_, err = tx.ExecContext(ctx, `UPDATE banner_revisions SET status = $1, updated_at = NOW() WHERE banner_id = $2 AND status = $3`,
+			models.RevisionArchived, id, models.RevisionPublished)
+		if err != nil {
+			return nil, err
+		}
+		contentJSON, err := json.Marshal(revision.Content)
+		if err != nil {
+			return nil, err
+		}
+		query := `UPDATE banners SET tag_ids = $1, feature_id = $2, content = $3, is_active = $4, published_revision = $5, updated_at = NOW() WHERE id = $6`
+		_, err = tx.ExecContext(ctx, query, pq.Array(revision.TagIds), revision.FeatureId, contentJSON, revision.IsActive, revision.ID, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE banner_revisions SET status = $1, updated_at = NOW() WHERE id = $2`, rule.to, revision.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = insertTransition(ctx, tx, int32(id), revision.ID, action, revision.Status, rule.to, actor, comment)
+	if err != nil {
+		return nil, err
+	}
+	err = insertAudit(ctx, tx, tenant, actor, models.AuditTransition, int32(id), before, after)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	revision.Status = rule.to
+	return revision, nil
+}
+
+func GetBannerHistory(ctx context.Context, tenant string, id int) (*models.BannerHistory, error) {
+	ctx, done := startQuery(ctx, "get_banner_history")
+	defer done()
+	history := models.BannerHistory{
+		Revisions:   []models.BannerRevision{},
+		Transitions: []models.BannerTransition{},
+	}
+	exists, err := BannerExists(ctx, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrBannerNotFound
+	}
+	rows, err := db.QueryContext(ctx, `SELECT `+revisionColumns+` FROM banner_revisions WHERE banner_id = $1 ORDER BY revision`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		history.Revisions = append(history.Revisions, *revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, banner_id, revision_id, action, from_status, to_status, actor, comment, created_at
+		FROM banner_transitions WHERE banner_id = $1 ORDER BY id`
+	transitionRows, err := db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer transitionRows.Close()
+	for transitionRows.Next() {
+		var transition models.BannerTransition
+		err := transitionRows.Scan(&transition.ID, &transition.BannerId, &transition.RevisionId, &transition.Action,
+			&transition.FromStatus, &transition.ToStatus, &transition.Actor, &transition.Comment, &transition.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		history.Transitions = append(history.Transitions, transition)
+	}
+	return &history, transitionRows.Err()
+}
+
+// GetBannerPreview возвращает последнюю ревизию баннера независимо от ее статуса
+func GetBannerPreview(ctx context.Context, tenant string, id int) (*models.BannerExpanded, error) {
+	ctx, done := startQuery(ctx, "get_banner_preview")
+	defer done()
+	query := `SELECT ` + revisionColumns + ` FROM banner_revisions
+		WHERE banner_id = (SELECT id FROM banners WHERE id = $1 AND tenant_id = $2) ORDER BY revision DESC LIMIT 1`
+	revision, err := scanRevision(db.QueryRowContext(ctx, query, id, tenant))
+	if err == sql.ErrNoRows {
+		return nil, ErrBannerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.BannerExpanded{
+		ID:        revision.BannerId,
+		TagIds:    revision.TagIds,
+		FeatureId: revision.FeatureId,
+		Content:   revision.Content,
+		IsActive:  revision.IsActive,
+		CreatedAt: revision.CreatedAt,
+		UpdatedAt: revision.UpdatedAt,
+	}, nil
+}
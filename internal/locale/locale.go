@@ -0,0 +1,153 @@
+package locale
+
+import (
+	"my_app/internal/config"
+	"my_app/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentKey - единственный ключ content локализованного баннера:
+// {"locales": {"en": {...}, "ru": {...}}}
+const ContentKey = "locales"
+
+var fallback []string
+
+func InitLocale(cfg config.Locale) {
+	fallback = nil
+	for _, tag := range cfg.Fallback {
+		if tag = Normalize(tag); tag != "" {
+			fallback = append(fallback, tag)
+		}
+	}
+}
+
+func Normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// ParseAcceptLanguage возвращает языки из заголовка Accept-Language в порядке убывания q
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := Normalize(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || name != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, tag.tag)
+	}
+	return result
+}
+
+// Candidates раскрывает запрошенные локали до базовых языков (en-us -> en)
+// и дополняет их цепочкой LOCALE_FALLBACK
+func Candidates(requested []string) []string {
+	var candidates []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		for tag != "" {
+			if !seen[tag] {
+				seen[tag] = true
+				candidates = append(candidates, tag)
+			}
+			i := strings.LastIndex(tag, "-")
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	for _, tag := range requested {
+		add(Normalize(tag))
+	}
+	for _, tag := range fallback {
+		add(tag)
+	}
+	return candidates
+}
+
+// Variants возвращает варианты content по локалям, если content локализован
+func Variants(content models.ModelMap) (map[string]models.ModelMap, bool) {
+	if len(content) != 1 {
+		return nil, false
+	}
+	raw, ok := content[ContentKey].(map[string]interface{})
+	if !ok || len(raw) == 0 {
+		return nil, false
+	}
+	variants := make(map[string]models.ModelMap, len(raw))
+	for tag, value := range raw {
+		variant, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		variants[tag] = variant
+	}
+	return variants, true
+}
+
+// Resolve выбирает первую доступную локаль из candidates
+func Resolve(candidates []string, available []string) (string, bool) {
+	normalized := make(map[string]string, len(available))
+	for _, tag := range available {
+		normalized[Normalize(tag)] = tag
+	}
+	for _, candidate := range candidates {
+		if tag, ok := normalized[candidate]; ok {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// Localize оставляет в баннере только content выбранной локали.
+// Для нелокализованного баннера возвращается он сам с пустой локалью.
+func Localize(banner *models.BannerExpanded, candidates []string) (*models.LocalizedBanner, bool) {
+	variants, ok := Variants(banner.Content)
+	if !ok {
+		return &models.LocalizedBanner{BannerExpanded: *banner}, true
+	}
+	available := make([]string, 0, len(variants))
+	for tag := range variants {
+		available = append(available, tag)
+	}
+	sort.Strings(available)
+	resolved, ok := Resolve(candidates, available)
+	if !ok {
+		return nil, false
+	}
+	localized := models.LocalizedBanner{
+		BannerExpanded: *banner,
+		Locale:         resolved,
+		Locales:        available,
+	}
+	localized.Content = variants[resolved]
+	return &localized, true
+}
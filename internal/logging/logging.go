@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"log/slog"
+	"my_app/internal/config"
+	"os"
+	"regexp"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIdKey struct{}
+
+// Идентификатор из заголовка X-Request-ID принимается, только если его безопасно
+// вернуть в ответе и записать в лог
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// InitLogging настраивает slog: JSON в stdout с уровнем debug, info, warn или error.
+// Вывод пакета log тоже идет через slog
+func InitLogging(cfg config.Logging) {
+	var level slog.Level
+	if cfg.Level != "" {
+		err := level.UnmarshalText([]byte(cfg.Level))
+		if err != nil {
+			log.Fatalf("log level: %v", err)
+		}
+	}
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(contextHandler{handler}))
+}
+
+// WithRequestID сохраняет идентификатор запроса в контексте
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+// RequestID возвращает идентификатор запроса из контекста или пустую строку
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+// ValidRequestID проверяет идентификатор, пришедший от клиента
+func ValidRequestID(id string) bool {
+	return requestIdPattern.MatchString(id)
+}
+
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// contextHandler добавляет к записям идентификатор запроса и трассировки из контекста,
+// поэтому логировать нужно через *Context функции slog
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "banner_service"
+
+// Registry содержит только метрики сервиса, рантайма Go и процесса
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// result: hit, miss или error
+	CacheRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Banner cache lookups by result.",
+	}, []string{"result"})
+
+	CacheAsyncWrites = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_async_writes_total",
+		Help:      "Goroutines spawned to write banners to cache.",
+	})
+
+	CacheAsyncWritesInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_async_writes_in_flight",
+		Help:      "Cache write goroutines that have not finished yet.",
+	})
+
+	DBQueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database operation latency by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"query"})
+
+	// target: primary или replica
+	DBReads = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_reads_total",
+		Help:      "Read queries by the database they were routed to.",
+	}, []string{"target"})
+
+	DBReplicaLag = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "db_replica_lag_seconds",
+		Help:      "Replication lag of the read replica at the last check.",
+	})
+
+	// 0 - замкнут, 1 - пробный вызов, 2 - разомкнут
+	BreakerState = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_state",
+		Help:      "Circuit breaker state by dependency: 0 closed, 1 half-open, 2 open.",
+	}, []string{"name"})
+
+	BreakerRejections = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_rejections_total",
+		Help:      "Calls rejected without reaching the dependency because its breaker was open.",
+	}, []string{"name"})
+)
+
+var dbStats = make(map[string]prometheus.Collector)
+
+// RegisterDBStats публикует статистику пула соединений под именем name, значения читаются
+// при каждом сборе. Повторный вызов с тем же именем заменяет пул, например после переподключения
+func RegisterDBStats(db *sql.DB, name string) {
+	if collector, ok := dbStats[name]; ok {
+		Registry.Unregister(collector)
+	}
+	dbStats[name] = collectors.NewDBStatsCollector(db, name)
+	Registry.MustRegister(dbStats[name])
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
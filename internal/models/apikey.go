@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+type APIKey struct {
+	ID     int32    `json:"id"`
+	Tenant string   `json:"tenant"`
+	Name   string   `json:"name"`
+	Prefix string   `json:"prefix"`
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes"`
+	// Хэш ключа, наружу не отдается
+	Hash       string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type APIKeyRequest struct {
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type APIKeyCreated struct {
+	APIKey
+	// Ключ показывается только один раз при создании и ротации
+	Key string `json:"key"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate     AuditAction = "create"
+	AuditUpdate     AuditAction = "update"
+	AuditDelete     AuditAction = "delete"
+	AuditImport     AuditAction = "import"
+	AuditTransition AuditAction = "transition"
+	// Действия с API ключами
+	AuditAPIKeyCreate AuditAction = "api_key_create"
+	AuditAPIKeyRotate AuditAction = "api_key_rotate"
+	AuditAPIKeyRevoke AuditAction = "api_key_revoke"
+)
+
+type AuditRecord struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor"`
+	Action    AuditAction     `json:"action"`
+	BannerId  *int32          `json:"banner_id"`
+	APIKeyId  *int32          `json:"api_key_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type AuditFilter struct {
+	Actor    string
+	Action   AuditAction
+	BannerId *int
+	APIKeyId *int
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
@@ -36,6 +36,55 @@ type BannerNoId struct {
 	IsActive  bool     `json:"is_active,omitempty"`
 }
 
+// ErrorResponse - тело любого ответа с ошибкой
 type ErrorResponse struct {
-	Error string `json:"error,omitempty"`
+	// Стабильный код ошибки, один из ErrorCode*
+	Code string `json:"code"`
+	// Описание ошибки для человека
+	Error string `json:"error"`
+	// Идентификатор запроса из заголовка X-Request-ID
+	RequestId string       `json:"request_id,omitempty"`
+	Fields    []FieldError `json:"fields,omitempty"`
+}
+
+const (
+	ErrorCodeBadRequest       = "bad_request"
+	ErrorCodeValidation       = "validation_failed"
+	ErrorCodeUnauthorized     = "unauthorized"
+	ErrorCodeForbidden        = "forbidden"
+	ErrorCodeNotFound         = "not_found"
+	ErrorCodeMethodNotAllowed = "method_not_allowed"
+	ErrorCodeConflict         = "conflict"
+	ErrorCodeIdempotencyKey   = "idempotency_key_reused"
+	ErrorCodeRateLimited      = "rate_limited"
+	ErrorCodeInternal         = "internal_error"
+	ErrorCodeUnavailable      = "unavailable"
+)
+
+type FieldError struct {
+	// JSON Pointer на поле с ошибкой
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
+
+type LocalizedBanner struct {
+	BannerExpanded
+	// Выбранная локаль, пустая для нелокализованного баннера
+	Locale string `json:"locale,omitempty"`
+	// Все локали баннера
+	Locales []string `json:"locales,omitempty"`
+	// Разобранные шаблоны строк content
+	Templates []TemplateField `json:"templates,omitempty"`
+}
+
+type TemplatePart struct {
+	Text    string `json:"text,omitempty"`
+	Var     string `json:"var,omitempty"`
+	Default string `json:"default,omitempty"`
+}
+
+type TemplateField struct {
+	// Путь до строки внутри content, индексы массивов записаны строками
+	Path  []string       `json:"path"`
+	Parts []TemplatePart `json:"parts"`
 }
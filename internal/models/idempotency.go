@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	StatusCode  int
+	Response    []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
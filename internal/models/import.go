@@ -0,0 +1,14 @@
+package models
+
+type ImportResult struct {
+	DryRun    bool    `json:"dry_run"`
+	Total     int     `json:"total"`
+	Imported  int     `json:"imported"`
+	BannerIds []int32 `json:"banner_ids,omitempty"`
+}
+
+// ImportedBanner - баннер из строки импорта, Row - номер строки во входных данных, с 1
+type ImportedBanner struct {
+	Row    int
+	Banner BannerNoId
+}
@@ -0,0 +1,10 @@
+package models
+
+import (
+	"time"
+)
+
+type PreviewResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type FeatureSchema struct {
+	FeatureId int32           `json:"feature_id"`
+	Schema    json.RawMessage `json:"schema"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type InvalidBanner struct {
+	BannerId int32        `json:"banner_id"`
+	Fields   []FieldError `json:"fields"`
+}
+
+type SchemaValidationReport struct {
+	FeatureId int32           `json:"feature_id"`
+	Checked   int             `json:"checked"`
+	Invalid   []InvalidBanner `json:"invalid"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+)
+
+type RevisionStatus string
+
+const (
+	RevisionDraft     RevisionStatus = "draft"
+	RevisionInReview  RevisionStatus = "in_review"
+	RevisionApproved  RevisionStatus = "approved"
+	RevisionPublished RevisionStatus = "published"
+	// Ранее опубликованная ревизия, замененная более новой
+	RevisionArchived RevisionStatus = "archived"
+)
+
+type TransitionAction string
+
+const (
+	// Создание новой ревизии в статусе черновика
+	ActionCreate   TransitionAction = "create"
+	ActionSubmit   TransitionAction = "submit"
+	ActionWithdraw TransitionAction = "withdraw"
+	ActionApprove  TransitionAction = "approve"
+	ActionReject   TransitionAction = "reject"
+	ActionPublish  TransitionAction = "publish"
+)
+
+type BannerRevision struct {
+	ID        int32          `json:"revision_id"`
+	BannerId  int32          `json:"banner_id"`
+	Revision  int32          `json:"revision"`
+	TagIds    []int32        `json:"tag_ids"`
+	FeatureId int32          `json:"feature_id"`
+	Content   ModelMap       `json:"content"`
+	IsActive  bool           `json:"is_active"`
+	Status    RevisionStatus `json:"status"`
+	CreatedBy string         `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type BannerTransition struct {
+	ID         int32            `json:"id"`
+	BannerId   int32            `json:"banner_id"`
+	RevisionId int32            `json:"revision_id"`
+	Action     TransitionAction `json:"action"`
+	FromStatus RevisionStatus   `json:"from_status"`
+	ToStatus   RevisionStatus   `json:"to_status"`
+	Actor      string           `json:"actor"`
+	Comment    string           `json:"comment,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+type BannerHistory struct {
+	Revisions   []BannerRevision   `json:"revisions"`
+	Transitions []BannerTransition `json:"transitions"`
+}
@@ -0,0 +1,86 @@
+package preview
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"my_app/internal/config"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTTL    = time.Hour
+	defaultMaxTTL = 24 * time.Hour
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid preview token")
+	ErrExpiredToken = errors.New("preview token expired")
+)
+
+var secret []byte
+var MaxTTL time.Duration
+
+type claims struct {
+	Tenant    string `json:"tenant,omitempty"`
+	BannerId  int32  `json:"banner_id"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func InitPreview(cfg config.Preview) {
+	secret = []byte(cfg.Secret)
+	if len(secret) == 0 {
+		// Ссылки должны проверяться на всех репликах и после перезапуска
+		log.Fatal("PREVIEW_SECRET is not set")
+	}
+	MaxTTL = cfg.MaxTTL
+	if MaxTTL <= 0 {
+		MaxTTL = defaultMaxTTL
+	}
+}
+
+// Sign выпускает токен вида base64(payload).base64(HMAC-SHA256(payload)),
+// токен действует только для баннера указанного тенанта
+func Sign(tenant string, bannerId int32, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	payload, err := json.Marshal(claims{Tenant: tenant, BannerId: bannerId, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + sign(encoded), expiresAt, nil
+}
+
+// Verify возвращает тенант и баннер токена, у токенов без тенанта он пустой
+func Verify(token string) (string, int32, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return "", 0, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(sign(parts[0])), []byte(parts[1])) {
+		return "", 0, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", 0, ErrInvalidToken
+	}
+	var c claims
+	err = json.Unmarshal(payload, &c)
+	if err != nil {
+		return "", 0, ErrInvalidToken
+	}
+	if time.Now().Unix() >= c.ExpiresAt {
+		return "", 0, ErrExpiredToken
+	}
+	return c.Tenant, c.BannerId, nil
+}
+
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxMemoryBuckets - верхняя граница числа корзин, при ее достижении удаляются давно не использованные
+	MaxMemoryBuckets = 100000
+	// Сколько корзин с конца LRU списка просматривается при вытеснении
+	evictScan = 100
+)
+
+type bucket struct {
+	key       string
+	tokens    float64
+	updatedAt time.Time
+	// Момент, когда корзина наполнится заново. После него ее можно удалить,
+	// новая корзина даст клиенту столько же запросов
+	fullAt time.Time
+}
+
+// MemoryLimiter - token bucket в памяти процесса, лимит действует для каждой реплики отдельно
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	// Корзины от недавно использованных к давно не использованным
+	lru *list.List
+	now func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*list.Element), lru: list.New(), now: time.Now}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Period.Seconds()
+	var b *bucket
+	if element, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(element)
+		b = element.Value.(*bucket)
+	} else {
+		if len(l.buckets) >= MaxMemoryBuckets {
+			l.evict(now)
+		}
+		b = &bucket{key: key, tokens: capacity, updatedAt: now}
+		l.buckets[key] = l.lru.PushFront(b)
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updatedAt).Seconds()*rate)
+	b.updatedAt = now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.fullAt = now.Add(time.Duration((capacity - b.tokens) / rate * float64(time.Second)))
+	if allowed {
+		return true, 0, nil
+	}
+	retryAfter := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, retryAfter, nil
+}
+
+// Len возвращает число корзин в памяти
+func (l *MemoryLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// evict удаляет наполнившиеся корзины из конца LRU списка, а если таких нет -
+// самую давно не использованную, чтобы число корзин не превышало MaxMemoryBuckets
+func (l *MemoryLimiter) evict(now time.Time) {
+	evicted := false
+	element := l.lru.Back()
+	for i := 0; i < evictScan && element != nil; i++ {
+		prev := element.Prev()
+		if b := element.Value.(*bucket); !now.Before(b.fullAt) {
+			l.remove(element)
+			evicted = true
+		}
+		element = prev
+	}
+	if !evicted {
+		l.remove(l.lru.Back())
+	}
+}
+
+func (l *MemoryLimiter) remove(element *list.Element) {
+	delete(l.buckets, element.Value.(*bucket).key)
+	l.lru.Remove(element)
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"my_app/internal/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Limit - не более Requests запросов за Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+type Limiter interface {
+	// Allow учитывает запрос и при превышении лимита возвращает, через сколько повторить
+	Allow(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+var (
+	limiter      Limiter
+	defaultLimit *Limit
+	routeLimits  map[string]Limit
+)
+
+// InitRateLimit читает лимит для всех маршрутов cfg.Default и лимиты отдельных
+// маршрутов cfg.Routes (например "UserBannerGet=1000/1m,BannerPost=60/1m").
+// Без лимитов ограничение выключено
+func InitRateLimit(cfg config.RateLimit) {
+	routeLimits = make(map[string]Limit)
+	defaultLimit = nil
+	if value := cfg.Default; value != "" {
+		limit, err := ParseLimit(value)
+		if err != nil {
+			log.Fatalf("rate limit: %v", err)
+		}
+		defaultLimit = &limit
+	}
+	for _, item := range strings.Split(cfg.Routes, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		route, value, found := strings.Cut(item, "=")
+		if !found {
+			log.Fatalf("route rate limits: expected route=limit, got %q", item)
+		}
+		limit, err := ParseLimit(value)
+		if err != nil {
+			log.Fatalf("route rate limits: %s: %v", route, err)
+		}
+		routeLimits[strings.TrimSpace(route)] = limit
+	}
+
+	switch backend := cfg.Backend; backend {
+	case "", BackendMemory:
+		limiter = NewMemoryLimiter()
+	case BackendRedis:
+		// Общий лимит для всех реплик, Redis подключается в cache.InitCache
+		limiter = &RedisLimiter{}
+	default:
+		log.Fatalf("rate limit backend must be %s or %s, got %q", BackendMemory, BackendRedis, backend)
+	}
+}
+
+// ParseLimit разбирает лимит вида "100/1s"
+func ParseLimit(value string) (Limit, error) {
+	requests, period, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return Limit{}, fmt.Errorf("expected requests/period, got %q", value)
+	}
+	var limit Limit
+	var err error
+	limit.Requests, err = strconv.Atoi(requests)
+	if err != nil || limit.Requests <= 0 {
+		return Limit{}, fmt.Errorf("invalid number of requests %q", requests)
+	}
+	limit.Period, err = time.ParseDuration(period)
+	if err != nil || limit.Period <= 0 {
+		return Limit{}, fmt.Errorf("invalid period %q", period)
+	}
+	return limit, nil
+}
+
+// RouteLimit возвращает лимит маршрута, ok = false если маршрут не ограничен
+func RouteLimit(route string) (Limit, bool) {
+	if limit, ok := routeLimits[route]; ok {
+		return limit, true
+	}
+	if defaultLimit != nil {
+		return *defaultLimit, true
+	}
+	return Limit{}, false
+}
+
+// Allow проверяет лимит маршрута для клиента
+func Allow(ctx context.Context, route string, client string) (bool, time.Duration, error) {
+	limit, ok := RouteLimit(route)
+	if !ok || limiter == nil {
+		return true, 0, nil
+	}
+	return limiter.Allow(ctx, route+":"+client, limit)
+}
@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"my_app/internal/cache"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Скользящее окно: в sorted set хранятся времена запросов за последний период.
+// Возвращает 0, если запрос учтен, иначе сколько миллисекунд ждать
+var slidingWindow = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+if redis.call('ZCARD', KEYS[1]) < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return 0
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return math.max(tonumber(oldest[2]) + window - now, 1)
+`)
+
+// RedisLimiter - скользящее окно в Redis, лимит общий для всех реплик
+type RedisLimiter struct{}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	member := make([]byte, 8)
+	_, err := rand.Read(member)
+	if err != nil {
+		return false, 0, err
+	}
+	now := time.Now().UnixMilli()
+	wait, err := slidingWindow.Run(ctx, cache.Client(), []string{"ratelimit:" + key},
+		now, limit.Period.Milliseconds(), limit.Requests, hex.EncodeToString(member)).Int64()
+	if err != nil {
+		return false, 0, err
+	}
+	if wait > 0 {
+		return false, time.Duration(wait) * time.Millisecond, nil
+	}
+	return true, 0, nil
+}
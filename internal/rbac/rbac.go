@@ -0,0 +1,218 @@
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"my_app/internal/auth"
+	"my_app/internal/config"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Scope string
+
+const (
+	// Получение баннера пользователем
+	ScopeBannerRead Scope = "banner:read"
+	// Просмотр всех баннеров, выгрузка и история, включая неактивные и неопубликованные
+	ScopeBannerList    Scope = "banner:list"
+	ScopeBannerWrite   Scope = "banner:write"
+	ScopeBannerDelete  Scope = "banner:delete"
+	ScopeBannerApprove Scope = "banner:approve"
+	ScopeBannerPublish Scope = "banner:publish"
+	// Выпуск ссылок предпросмотра, из встроенных ролей есть только у админа
+	ScopeBannerPreview Scope = "banner:preview"
+	ScopeSchemaWrite   Scope = "schema:write"
+	ScopeAuditRead     Scope = "audit:read"
+	ScopeAPIKeyManage  Scope = "apikey:manage"
+	// Все права
+	ScopeAll Scope = "*"
+)
+
+var knownScopes = map[Scope]bool{
+	ScopeBannerRead:    true,
+	ScopeBannerList:    true,
+	ScopeBannerWrite:   true,
+	ScopeBannerDelete:  true,
+	ScopeBannerApprove: true,
+	ScopeBannerPublish: true,
+	ScopeBannerPreview: true,
+	ScopeSchemaWrite:   true,
+	ScopeAuditRead:     true,
+	ScopeAPIKeyManage:  true,
+	ScopeAll:           true,
+}
+
+// Grant - право scope, ограниченное фичами. Записывается как "banner:write" или "banner:write@1,2"
+type Grant struct {
+	Scope Scope
+	// nil - все фичи
+	Features map[int32]bool
+}
+
+type Permissions []Grant
+
+var defaultRoles = map[string][]string{
+	"user":     {"banner:read"},
+	"editor":   {"banner:read", "banner:list", "banner:write"},
+	"approver": {"banner:read", "banner:list", "banner:approve", "banner:publish"},
+	"admin":    {"*"},
+}
+
+var roles map[string]Permissions
+
+// InitRBAC загружает роли по умолчанию и роли из JSON файла cfg.RolesFile:
+// {"team_a_editor": ["banner:read", "banner:write@1,2"]}
+func InitRBAC(cfg config.RBAC) {
+	definitions := make(map[string][]string, len(defaultRoles))
+	for role, scopes := range defaultRoles {
+		definitions[role] = scopes
+	}
+	if path := cfg.RolesFile; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var custom map[string][]string
+		err = json.Unmarshal(data, &custom)
+		if err != nil {
+			log.Fatalf("rbac roles file: %v", err)
+		}
+		for role, scopes := range custom {
+			definitions[role] = scopes
+		}
+	}
+
+	roles = make(map[string]Permissions, len(definitions))
+	for role, scopes := range definitions {
+		permissions, err := ParseGrants(scopes)
+		if err != nil {
+			log.Fatalf("rbac role %q: %v", role, err)
+		}
+		roles[role] = permissions
+	}
+}
+
+func KnownRole(role string) bool {
+	_, ok := roles[role]
+	return ok
+}
+
+// RolePermissions возвращает права роли, для неизвестной роли - nil
+func RolePermissions(role string) Permissions {
+	return roles[role]
+}
+
+func ParseGrant(value string) (Grant, error) {
+	scope, features, limited := strings.Cut(strings.TrimSpace(value), "@")
+	grant := Grant{Scope: Scope(scope)}
+	if !knownScopes[grant.Scope] {
+		return grant, fmt.Errorf("unknown scope %q", scope)
+	}
+	if !limited {
+		return grant, nil
+	}
+	grant.Features = make(map[int32]bool)
+	for _, feature := range strings.Split(features, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(feature), 10, 32)
+		if err != nil {
+			return grant, fmt.Errorf("invalid feature id in %q", value)
+		}
+		grant.Features[int32(id)] = true
+	}
+	return grant, nil
+}
+
+func ParseGrants(values []string) (Permissions, error) {
+	permissions := make(Permissions, 0, len(values))
+	for _, value := range values {
+		grant, err := ParseGrant(value)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, grant)
+	}
+	return permissions, nil
+}
+
+// ForPrincipal объединяет права ролей и scopes, выданные напрямую токену или API ключу.
+// Непонятные scopes пропускаются, чтобы чужие claim не ломали авторизацию.
+func ForPrincipal(principal *auth.Principal) Permissions {
+	var permissions Permissions
+	for _, role := range principal.Roles {
+		permissions = append(permissions, roles[role]...)
+	}
+	for _, value := range principal.Scopes {
+		grant, err := ParseGrant(value)
+		if err == nil {
+			permissions = append(permissions, grant)
+		}
+	}
+	return permissions
+}
+
+func (g Grant) covers(scope Scope) bool {
+	return g.Scope == scope || g.Scope == ScopeAll
+}
+
+// Allows проверяет scope хотя бы для одной фичи
+func (p Permissions) Allows(scope Scope) bool {
+	for _, grant := range p {
+		if grant.covers(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Permissions) AllowsFeature(scope Scope, featureId int32) bool {
+	for _, grant := range p {
+		if grant.covers(scope) && (grant.Features == nil || grant.Features[featureId]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers проверяет, что p дает все права из other с учетом ограничений по фичам.
+// "*" покрывается только "*", а не набором отдельных scopes
+func (p Permissions) Covers(other Permissions) bool {
+	for _, grant := range other {
+		if grant.Features == nil {
+			if _, all := p.Features(grant.Scope); !all {
+				return false
+			}
+			continue
+		}
+		for featureId := range grant.Features {
+			if !p.AllowsFeature(grant.Scope, featureId) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Features возвращает фичи, на которые выдан scope, all = true если ограничений нет
+func (p Permissions) Features(scope Scope) (features []int32, all bool) {
+	set := make(map[int32]bool)
+	for _, grant := range p {
+		if !grant.covers(scope) {
+			continue
+		}
+		if grant.Features == nil {
+			return nil, true
+		}
+		for id := range grant.Features {
+			set[id] = true
+		}
+	}
+	for id := range set {
+		features = append(features, id)
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i] < features[j] })
+	return features, false
+}
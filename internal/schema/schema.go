@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"my_app/internal/locale"
+	"my_app/internal/models"
+	"sort"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const schemaURL = "feature_schema.json"
+
+type Schema = jsonschema.Schema
+
+func Compile(raw []byte) (*Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	// Схемы задаются через API, поэтому внешние $ref запрещены
+	compiler.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("external references are not allowed: %s", s)
+	}
+	err := compiler.AddResource(schemaURL, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	return compiler.Compile(schemaURL)
+}
+
+// ValidateContent проверяет content по схеме. У локализованного content
+// по схеме проверяется вариант каждой локали.
+func ValidateContent(schema *Schema, content models.ModelMap) ([]models.FieldError, error) {
+	variants, ok := locale.Variants(content)
+	if !ok {
+		return validate(schema, content, "/content")
+	}
+	tags := make([]string, 0, len(variants))
+	for tag := range variants {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	var fieldErrors []models.FieldError
+	for _, tag := range tags {
+		errs, err := validate(schema, variants[tag], "/content/"+locale.ContentKey+"/"+tag)
+		if err != nil {
+			return nil, err
+		}
+		fieldErrors = append(fieldErrors, errs...)
+	}
+	return fieldErrors, nil
+}
+
+func validate(schema *Schema, content models.ModelMap, prefix string) ([]models.FieldError, error) {
+	err := schema.Validate(map[string]interface{}(content))
+	if err == nil {
+		return nil, nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil, err
+	}
+	var fieldErrors []models.FieldError
+	collectFieldErrors(validationErr, prefix, &fieldErrors)
+	return fieldErrors, nil
+}
+
+func collectFieldErrors(err *jsonschema.ValidationError, prefix string, fieldErrors *[]models.FieldError) {
+	if len(err.Causes) == 0 {
+		*fieldErrors = append(*fieldErrors, models.FieldError{
+			Field: prefix + err.InstanceLocation,
+			Error: err.Message,
+		})
+		return
+	}
+	for _, cause := range err.Causes {
+		collectFieldErrors(cause, prefix, fieldErrors)
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"my_app/api"
+	"my_app/internal/apikey"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func (Handlers) APIKeysGet(w http.ResponseWriter, r *http.Request) {
+	keys, err := db.GetAPIKeys(r.Context(), tenantFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keys)
+}
+
+func (Handlers) APIKeyPost(w http.ResponseWriter, r *http.Request) {
+	// Получение параметров запроса
+	var body api.APIKeyPostJSONRequestBody
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+	request := models.APIKeyRequest{Name: body.Name, Role: body.Role, ExpiresAt: body.ExpiresAt}
+	if body.Scopes != nil {
+		request.Scopes = *body.Scopes
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		badRequest(w, r, "name is required")
+		return
+	}
+	if !rbac.KnownRole(request.Role) {
+		badRequest(w, r, "unknown role "+request.Role)
+		return
+	}
+	grants, err := rbac.ParseGrants(request.Scopes)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+	// Ключ не может получить права, которых нет у того, кто его выпускает
+	requested := append(append(rbac.Permissions{}, rbac.RolePermissions(request.Role)...), grants...)
+	if !permissionsFromRequest(r).Covers(requested) {
+		writeErrorResponse(w, r, http.StatusForbidden, models.ErrorCodeForbidden, "api key role and scopes must not exceed caller permissions", nil)
+		return
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		badRequest(w, r, "expires_at must be in the future")
+		return
+	}
+	if request.Scopes == nil {
+		request.Scopes = []string{}
+	}
+
+	// Сохраняется только хэш, сам ключ возвращается один раз
+	var response models.APIKeyCreated
+	var prefix, hash string
+	response.Key, prefix, hash, err = apikey.Generate()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	key, err := db.CreateAPIKey(r.Context(), tenantFromRequest(r), request, prefix, hash, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	response.APIKey = *key
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (Handlers) APIKeyRotatePost(w http.ResponseWriter, r *http.Request, id int) {
+	var response models.APIKeyCreated
+	var prefix, hash string
+	var err error
+	response.Key, prefix, hash, err = apikey.Generate()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	key, oldHash, err := db.RotateAPIKey(r.Context(), tenantFromRequest(r), id, prefix, hash, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	apikey.Forget(oldHash)
+	response.APIKey = *key
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (Handlers) APIKeyDelete(w http.ResponseWriter, r *http.Request, id int) {
+	hash, err := db.RevokeAPIKey(r.Context(), tenantFromRequest(r), id, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	apikey.Forget(hash)
+	w.WriteHeader(http.StatusNoContent)
+}
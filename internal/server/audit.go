@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/json"
+	"my_app/api"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"net/http"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+func (Handlers) AuditGet(w http.ResponseWriter, r *http.Request, params api.AuditGetParams) {
+	// Получение параметров запроса
+	filter := models.AuditFilter{
+		BannerId: params.BannerId,
+		APIKeyId: params.ApiKeyId,
+		From:     params.From,
+		To:       params.To,
+		Limit:    defaultAuditLimit,
+	}
+	if params.Actor != nil {
+		filter.Actor = *params.Actor
+	}
+	if params.Action != nil {
+		filter.Action = models.AuditAction(*params.Action)
+	}
+	if params.Limit != nil {
+		if *params.Limit < 1 || *params.Limit > maxAuditLimit {
+			badRequest(w, r, "limit must be between 1 and 1000")
+			return
+		}
+		filter.Limit = *params.Limit
+	}
+	if params.Offset != nil {
+		if *params.Offset < 0 {
+			badRequest(w, r, "Invalid offset value")
+			return
+		}
+		filter.Offset = *params.Offset
+	}
+
+	// Получение записей аудита из базы данных
+	records, err := db.GetAuditLog(r.Context(), tenantFromRequest(r), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(records)
+}
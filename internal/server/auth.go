@@ -2,21 +2,52 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"my_app/internal/apikey"
+	"my_app/internal/auth"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
 	"net/http"
+	"strings"
 )
 
 type AccessCheckFunc func(*http.Request) bool
 
-func AuthMiddleware(check AccessCheckFunc) func(http.Handler) http.Handler {
+// AuthMiddleware проверяет токен или API ключ и права на маршрут route.
+// Неудачные попытки учитываются в лимите маршрута по адресу клиента, так ограничивается перебор токенов
+func AuthMiddleware(route string, check AccessCheckFunc) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			token := r.Header.Get("token")
+			token := bearerToken(r)
 			if token == "" {
-				w.WriteHeader(http.StatusUnauthorized)
+				if allowRequest(w, r, route, clientAddr(r)) {
+					writeAuthError(w, r, http.StatusUnauthorized, "authentication token is required")
+				}
 				return
 			}
+			var principal *auth.Principal
+			var err error
+			if features.APIKeys && apikey.IsAPIKey(token) {
+				principal, err = apikey.Authenticate(r.Context(), token)
+				if err != nil && !errors.Is(err, apikey.ErrInvalidKey) && !errors.Is(err, apikey.ErrExpiredKey) && !errors.Is(err, apikey.ErrRevokedKey) {
+					writeError(w, r, err)
+					return
+				}
+			} else {
+				principal, err = auth.Verify(token)
+			}
+			if err != nil {
+				if allowRequest(w, r, route, clientAddr(r)) {
+					writeAuthError(w, r, http.StatusUnauthorized, "invalid token: "+err.Error())
+				}
+				return
+			}
+			ctx := context.WithValue(r.Context(), PrincipalKey, principal)
+			ctx = context.WithValue(ctx, PermissionsKey, rbac.ForPrincipal(principal))
+			r = r.WithContext(ctx)
 			if !check(r) {
-				w.WriteHeader(http.StatusForbidden)
+				writeAuthError(w, r, http.StatusForbidden, "token has no scope to access this resource")
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -24,45 +55,89 @@ func AuthMiddleware(check AccessCheckFunc) func(http.Handler) http.Handler {
 	}
 }
 
-type Role string
+// bearerToken берет JWT или API ключ из заголовка Authorization,
+// API ключ также из X-API-Key, а для старых клиентов токен из заголовка token
+func bearerToken(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	header := r.Header.Get("Authorization")
+	if scheme, token, found := strings.Cut(header, " "); found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return r.Header.Get("token")
+}
+
+func writeAuthError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="banner-service"`)
+	}
+	writeErrorResponse(w, r, status, errorCodes[status], message, nil)
+}
+
 type ContextKey string
 
 const (
-	RoleKey   ContextKey = "role"
-	AdminRole Role       = "admin"
-	UserRole  Role       = "user"
+	PrincipalKey   ContextKey = "principal"
+	PermissionsKey ContextKey = "permissions"
 )
 
-//todo: nice validation
+func principalFromRequest(r *http.Request) *auth.Principal {
+	principal, _ := r.Context().Value(PrincipalKey).(*auth.Principal)
+	return principal
+}
 
-func userAccessCheck(r *http.Request) bool {
-	token := r.Header.Get("token")
-	if token != "user_token" {
-		return false
+// tenantFromRequest возвращает тенант из токена, все данные запроса ограничены им
+func tenantFromRequest(r *http.Request) string {
+	principal := principalFromRequest(r)
+	if principal == nil || principal.Tenant == "" {
+		return auth.DefaultTenant
 	}
-	ctx := r.Context()
-	*r = *r.WithContext(context.WithValue(ctx, RoleKey, UserRole))
-	role, ok := r.Context().Value(RoleKey).(Role)
-	if !ok || role != UserRole {
-		return false
+	return principal.Tenant
+}
+
+func permissionsFromRequest(r *http.Request) rbac.Permissions {
+	permissions, _ := r.Context().Value(PermissionsKey).(rbac.Permissions)
+	return permissions
+}
+
+// scopeAccessCheck пропускает запрос, если scope выдан хотя бы для одной фичи.
+// Ограничение по фичам проверяют обработчики через authorizeFeature
+func scopeAccessCheck(scope rbac.Scope) AccessCheckFunc {
+	return func(r *http.Request) bool {
+		return permissionsFromRequest(r).Allows(scope)
 	}
-	return true
 }
 
-func adminAccessCheck(r *http.Request) bool {
-	token := r.Header.Get("token")
-	if token != "admin_token" {
-		return false
+// authorizeFeature проверяет scope для фич баннера и при отказе сам пишет ответ
+func authorizeFeature(w http.ResponseWriter, r *http.Request, scope rbac.Scope, featureIds ...int32) bool {
+	permissions := permissionsFromRequest(r)
+	for _, featureId := range featureIds {
+		if !permissions.AllowsFeature(scope, featureId) {
+			writeErrorResponse(w, r, http.StatusForbidden, models.ErrorCodeForbidden, fmt.Sprintf("no %s access to feature %d", scope, featureId), nil)
+			return false
+		}
 	}
-	ctx := r.Context()
-	*r = *r.WithContext(context.WithValue(ctx, RoleKey, AdminRole))
-	role, ok := r.Context().Value(RoleKey).(Role)
-	if !ok || role != AdminRole {
+	return true
+}
+
+// authorizeAllFeatures нужен запросам, не ограниченным одной фичей
+func authorizeAllFeatures(w http.ResponseWriter, r *http.Request, scope rbac.Scope) bool {
+	if _, all := permissionsFromRequest(r).Features(scope); !all {
+		writeErrorResponse(w, r, http.StatusForbidden, models.ErrorCodeForbidden, fmt.Sprintf("%s access is limited to some features, feature_id is required", scope), nil)
 		return false
 	}
 	return true
 }
 
-func userOrAdminAccessCheck(r *http.Request) bool {
-	return userAccessCheck(r) || adminAccessCheck(r)
+// actorFromRequest возвращает того, кто выполняет запрос, для истории изменений
+func actorFromRequest(r *http.Request) string {
+	principal := principalFromRequest(r)
+	if principal == nil {
+		return ""
+	}
+	if principal.Subject == "" && len(principal.Roles) > 0 {
+		return principal.Roles[0]
+	}
+	return principal.Subject
 }
@@ -0,0 +1,418 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"my_app/api"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/schema"
+	"my_app/internal/templating"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+
+	maxImportSize = 32 << 20
+)
+
+var csvHeader = []string{"banner_id", "tag_ids", "feature_id", "content", "is_active", "created_at", "updated_at"}
+
+type exportRow struct {
+	ID        int32           `json:"banner_id"`
+	TagIds    []int32         `json:"tag_ids"`
+	FeatureId int32           `json:"feature_id"`
+	Content   models.ModelMap `json:"content"`
+	IsActive  bool            `json:"is_active"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// importRowError - ошибка строки входных данных, строки нумеруются с 1
+type importRowError struct {
+	Row   int
+	Error string
+}
+
+type importRow struct {
+	TagIds    []int32         `json:"tag_ids"`
+	FeatureId *int32          `json:"feature_id"`
+	Content   models.ModelMap `json:"content"`
+	IsActive  *bool           `json:"is_active"`
+}
+
+// bulkFormat выбирает формат по параметру format, без него - по Content-Type или Accept
+func bulkFormat(r *http.Request, format string) (string, error) {
+	if format == "" {
+		contentType := r.Header.Get("Content-Type")
+		if r.Method == http.MethodGet {
+			contentType = r.Header.Get("Accept")
+		}
+		switch {
+		case strings.Contains(contentType, "text/csv"):
+			format = formatCSV
+		default:
+			format = formatNDJSON
+		}
+	}
+	if format != formatNDJSON && format != formatCSV {
+		return "", fmt.Errorf("unsupported format %q, expected %s or %s", format, formatNDJSON, formatCSV)
+	}
+	return format, nil
+}
+
+func (Handlers) BannerExport(w http.ResponseWriter, r *http.Request, params api.BannerExportParams) {
+	// Получение параметров запроса
+	var requested string
+	if params.Format != nil {
+		requested = string(*params.Format)
+	}
+	format, err := bulkFormat(r, requested)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+	if params.FeatureId != nil {
+		if !authorizeFeature(w, r, rbac.ScopeBannerList, int32(*params.FeatureId)) {
+			return
+		}
+	} else if !authorizeAllFeatures(w, r, rbac.ScopeBannerList) {
+		return
+	}
+
+	var write func(models.BannerExpanded) error
+	var flush func() error
+	switch format {
+	case formatCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=UTF-8")
+		writer := csv.NewWriter(w)
+		write = func(banner models.BannerExpanded) error {
+			return writer.Write(bannerToCSV(banner))
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writer.Write(csvHeader)
+	default:
+		w.Header().Set("Content-Type", "application/x-ndjson; charset=UTF-8")
+		encoder := json.NewEncoder(w)
+		write = func(banner models.BannerExpanded) error {
+			return encoder.Encode(exportRow(banner))
+		}
+		flush = func() error { return nil }
+		w.WriteHeader(http.StatusOK)
+	}
+	if err != nil {
+		// Статус уже отправлен, остается только прервать поток
+		slog.ErrorContext(r.Context(), "banner export failed", "rows", 0, "error", err)
+		return
+	}
+
+	// Потоковая выгрузка баннеров из базы данных, SERVER_WRITE_TIMEOUT на нее не действует,
+	// выгрузка прерывается только отменой запроса
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	flusher, _ := w.(http.Flusher)
+	count := 0
+	err = db.ExportBanners(r.Context(), tenantFromRequest(r), params.FeatureId, params.TagId, func(banner models.BannerExpanded) error {
+		err := write(banner)
+		if err != nil {
+			return err
+		}
+		count++
+		if flusher != nil && count%100 == 0 {
+			err = flush()
+			flusher.Flush()
+		}
+		return err
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// Статус уже отправлен, остается только прервать поток
+		slog.ErrorContext(r.Context(), "banner export failed", "rows", count, "error", err)
+	}
+}
+
+func (Handlers) BannerImport(w http.ResponseWriter, r *http.Request, params api.BannerImportParams) {
+	// Получение параметров запроса
+	var requested string
+	if params.Format != nil {
+		requested = string(*params.Format)
+	}
+	format, err := bulkFormat(r, requested)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+	dryRun := params.DryRun != nil && *params.DryRun
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	var rows []models.ImportedBanner
+	var rowErrors []importRowError
+	switch format {
+	case formatCSV:
+		rows, rowErrors, err = parseCSVImport(body)
+	default:
+		rows, rowErrors, err = parseNDJSONImport(body)
+	}
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	total := len(rows) + len(rowErrors)
+	featureIds := make([]int32, 0, len(rows))
+	for _, row := range rows {
+		featureIds = append(featureIds, row.Banner.FeatureId)
+	}
+	if !authorizeFeature(w, r, rbac.ScopeBannerWrite, featureIds...) {
+		return
+	}
+	// Проверка шаблонов и content по схемам фич
+	schemaErrors, err := validateImportContent(r, rows)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	rowErrors = append(rowErrors, schemaErrors...)
+	sort.SliceStable(rowErrors, func(i, j int) bool {
+		return rowErrors[i].Row < rowErrors[j].Row
+	})
+
+	if len(rowErrors) > 0 {
+		fields := make([]models.FieldError, 0, len(rowErrors))
+		for _, rowError := range rowErrors {
+			fields = append(fields, models.FieldError{Field: "row " + strconv.Itoa(rowError.Row), Error: rowError.Error})
+		}
+		writeErrorResponse(w, r, http.StatusBadRequest, models.ErrorCodeValidation,
+			fmt.Sprintf("%d of %d rows are invalid", len(rowErrors), total), fields)
+		return
+	}
+
+	result := models.ImportResult{
+		DryRun: dryRun,
+		Total:  total,
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if dryRun {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	// Запись всех баннеров в одной транзакции
+	result.BannerIds, err = db.ImportBanners(r.Context(), tenantFromRequest(r), rows, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	result.Imported = len(result.BannerIds)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+func bannerToCSV(banner models.BannerExpanded) []string {
+	tagIds, _ := json.Marshal(banner.TagIds)
+	content, _ := json.Marshal(banner.Content)
+	return []string{
+		strconv.Itoa(int(banner.ID)),
+		string(tagIds),
+		strconv.Itoa(int(banner.FeatureId)),
+		string(content),
+		strconv.FormatBool(banner.IsActive),
+		banner.CreatedAt.Format(time.RFC3339),
+		banner.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func validateImportContent(r *http.Request, rows []models.ImportedBanner) ([]importRowError, error) {
+	var rowErrors []importRowError
+	schemas := make(map[int32]*schema.Schema)
+	for _, row := range rows {
+		_, fields := templating.ParseContent(row.Banner.Content)
+		for _, field := range fields {
+			rowErrors = append(rowErrors, importRowError{Row: row.Row, Error: field.Field + ": " + field.Error})
+		}
+		contentSchema, ok := schemas[row.Banner.FeatureId]
+		if !ok {
+			var err error
+			contentSchema, err = featureSchema(r, row.Banner.FeatureId)
+			if err != nil {
+				return nil, err
+			}
+			schemas[row.Banner.FeatureId] = contentSchema
+		}
+		if contentSchema == nil {
+			continue
+		}
+		fields, err := schema.ValidateContent(contentSchema, row.Banner.Content)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range fields {
+			rowErrors = append(rowErrors, importRowError{Row: row.Row, Error: field.Field + ": " + field.Error})
+		}
+	}
+	return rowErrors, nil
+}
+
+func parseNDJSONImport(body io.Reader) ([]models.ImportedBanner, []importRowError, error) {
+	var banners []models.ImportedBanner
+	var rowErrors []importRowError
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var row importRow
+		err := json.Unmarshal(data, &row)
+		if err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: line, Error: err.Error()})
+			continue
+		}
+		banner, err := row.validate()
+		if err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: line, Error: err.Error()})
+			continue
+		}
+		banners = append(banners, models.ImportedBanner{Row: line, Banner: banner})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return banners, rowErrors, nil
+}
+
+func parseCSVImport(body io.Reader) ([]models.ImportedBanner, []importRowError, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"tag_ids", "feature_id", "content"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("csv header must contain column %q", name)
+		}
+	}
+
+	var banners []models.ImportedBanner
+	var rowErrors []importRowError
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: line, Error: err.Error()})
+			continue
+		}
+		row, err := csvImportRow(record, columns)
+		if err == nil {
+			var banner models.BannerNoId
+			banner, err = row.validate()
+			if err == nil {
+				banners = append(banners, models.ImportedBanner{Row: line, Banner: banner})
+				continue
+			}
+		}
+		rowErrors = append(rowErrors, importRowError{Row: line, Error: err.Error()})
+	}
+	return banners, rowErrors, nil
+}
+
+func csvImportRow(record []string, columns map[string]int) (*importRow, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var row importRow
+	if value := field("tag_ids"); value != "" {
+		err := json.Unmarshal([]byte(value), &row.TagIds)
+		if err != nil {
+			return nil, fmt.Errorf("tag_ids: %w", err)
+		}
+	}
+	if value := field("feature_id"); value != "" {
+		featureId, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("feature_id: %w", err)
+		}
+		id := int32(featureId)
+		row.FeatureId = &id
+	}
+	if value := field("content"); value != "" {
+		err := json.Unmarshal([]byte(value), &row.Content)
+		if err != nil {
+			return nil, fmt.Errorf("content: %w", err)
+		}
+	}
+	if value := field("is_active"); value != "" {
+		isActive, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("is_active: %w", err)
+		}
+		row.IsActive = &isActive
+	}
+	return &row, nil
+}
+
+func (row importRow) validate() (models.BannerNoId, error) {
+	var banner models.BannerNoId
+	if len(row.TagIds) == 0 {
+		return banner, fmt.Errorf("tag_ids must not be empty")
+	}
+	for _, tagId := range row.TagIds {
+		if tagId < 0 {
+			return banner, fmt.Errorf("tag_ids must not be negative")
+		}
+	}
+	if row.FeatureId == nil {
+		return banner, fmt.Errorf("feature_id is required")
+	}
+	if *row.FeatureId < 0 {
+		return banner, fmt.Errorf("feature_id must not be negative")
+	}
+	if row.Content == nil {
+		return banner, fmt.Errorf("content is required")
+	}
+	banner.TagIds = row.TagIds
+	banner.FeatureId = *row.FeatureId
+	banner.Content = row.Content
+	if row.IsActive != nil {
+		banner.IsActive = *row.IsActive
+	}
+	return banner, nil
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"my_app/api"
+	"my_app/internal/models"
+)
+
+// Тела запросов разбираются в модели, сгенерированные из api/api.yaml,
+// и переводятся в модели базы данных
+
+func int32s(values []int) []int32 {
+	result := make([]int32, 0, len(values))
+	for _, value := range values {
+		result = append(result, int32(value))
+	}
+	return result
+}
+
+func bannerFromRequest(request api.BannerPostJSONRequestBody) models.BannerNoId {
+	banner := models.BannerNoId{
+		TagIds:    int32s(request.TagIds),
+		FeatureId: int32(request.FeatureId),
+		Content:   request.Content,
+	}
+	if request.IsActive != nil {
+		banner.IsActive = *request.IsActive
+	}
+	return banner
+}
+
+// applyBannerPatch накладывает PATCH на текущую версию баннера,
+// отсутствующее поле и null оставляют значение без изменений
+func applyBannerPatch(current models.BannerExpanded, patch api.BannerIdPatchJSONRequestBody) models.BannerNoId {
+	banner := models.BannerNoId{
+		TagIds:    current.TagIds,
+		FeatureId: current.FeatureId,
+		Content:   current.Content,
+		IsActive:  current.IsActive,
+	}
+	if patch.TagIds != nil {
+		banner.TagIds = int32s(*patch.TagIds)
+	}
+	if patch.FeatureId != nil {
+		banner.FeatureId = int32(*patch.FeatureId)
+	}
+	if patch.Content != nil {
+		banner.Content = *patch.Content
+	}
+	if patch.IsActive != nil {
+		banner.IsActive = *patch.IsActive
+	}
+	return banner
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"my_app/internal/apperr"
+	"my_app/internal/logging"
+	"my_app/internal/models"
+	"net/http"
+)
+
+// errorCodes - код ошибки по умолчанию для статуса ответа
+var errorCodes = map[int]string{
+	http.StatusBadRequest:          models.ErrorCodeBadRequest,
+	http.StatusUnauthorized:        models.ErrorCodeUnauthorized,
+	http.StatusForbidden:           models.ErrorCodeForbidden,
+	http.StatusNotFound:            models.ErrorCodeNotFound,
+	http.StatusMethodNotAllowed:    models.ErrorCodeMethodNotAllowed,
+	http.StatusConflict:            models.ErrorCodeConflict,
+	http.StatusTooManyRequests:     models.ErrorCodeRateLimited,
+	http.StatusInternalServerError: models.ErrorCodeInternal,
+	http.StatusServiceUnavailable:  models.ErrorCodeUnavailable,
+}
+
+// writeErrorResponse пишет ответ с ошибкой. По code клиенты различают ошибки,
+// текст предназначен для людей и может меняться
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, status int, code string, message string, fields []models.FieldError) {
+	errorResponse := models.ErrorResponse{
+		Code:      code,
+		Error:     message,
+		RequestId: logging.RequestID(r.Context()),
+		Fields:    fields,
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse)
+}
+
+// writeError отвечает статусом, который соответствует виду ошибки: не найдено, конфликт,
+// неверный запрос или недоступность зависимостей. Ошибки без вида - внутренние
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := apperr.HTTPStatus(err)
+	writeErrorResponse(w, r, status, errorCodes[status], err.Error(), nil)
+}
+
+func badRequest(w http.ResponseWriter, r *http.Request, message string) {
+	writeErrorResponse(w, r, http.StatusBadRequest, models.ErrorCodeBadRequest, message, nil)
+}
+
+func notFound(w http.ResponseWriter, r *http.Request, message string) {
+	writeErrorResponse(w, r, http.StatusNotFound, models.ErrorCodeNotFound, message, nil)
+}
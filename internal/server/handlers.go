@@ -1,94 +1,83 @@
 package server
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"fmt"
+	"my_app/api"
 	"my_app/internal/db"
+	"my_app/internal/locale"
 	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/templating"
 	"net/http"
-	"strconv"
-	"strings"
-
-	"github.com/gorilla/mux"
 )
 
-func ValidateInt(param string) (*int, error) {
-	if param == "" {
-		return nil, fmt.Errorf("value required")
-	}
-	value, err := strconv.Atoi(param)
-	if err != nil {
-		return nil, err
+func (Handlers) UserBannerGet(w http.ResponseWriter, r *http.Request, params api.UserBannerGetParams) {
+	// Предпросмотр неопубликованного баннера по подписанной ссылке
+	if params.PreviewToken != nil && *params.PreviewToken != "" && features.Preview {
+		userBannerPreview(w, r, *params.PreviewToken)
+		return
 	}
-	return &value, nil
-}
-
-func UserBannerGet(w http.ResponseWriter, r *http.Request) {
-	// Получение параметров запроса
-	useLastRevision := r.URL.Query().Get("use_last_revision") == "true"
-	featureId, featureErr := ValidateInt(r.URL.Query().Get("feature_id"))
-	tagId, tagErr := ValidateInt(r.URL.Query().Get("tag_id"))
 	// Проверка наличия параметров
-	var errorResponse models.ErrorResponse
-	if featureErr != nil || tagErr != nil {
-		errorResponse.Error = "tag_id and feature_id are required"
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse)
+	if params.FeatureId == nil || params.TagId == nil {
+		badRequest(w, r, "tag_id and feature_id are required")
 		return
 	}
-	isAdmin := false
-	role, ok := r.Context().Value(RoleKey).(Role)
-	if ok {
-		isAdmin = role == AdminRole
+	useLastRevision := params.UseLastRevision != nil && *params.UseLastRevision
+	if !authorizeFeature(w, r, rbac.ScopeBannerRead, int32(*params.FeatureId)) {
+		return
 	}
+	// Неактивные баннеры видны тем, кто может просматривать все баннеры фичи
+	isAdmin := permissionsFromRequest(r).AllowsFeature(rbac.ScopeBannerList, int32(*params.FeatureId))
 	// Получение баннера из базы данных
-	bannerContent, err := db.GetBannerForUser(featureId, tagId, useLastRevision, isAdmin)
+	banner, err := db.GetBannerForUser(r.Context(), tenantFromRequest(r), params.FeatureId, params.TagId, requestedLocales(r), useLastRevision, isAdmin)
 	if err != nil {
-		if strings.Contains(err.Error(), "no banner found") {
-			w.WriteHeader(http.StatusNotFound)
-		} else {
-			// Обработка других типов ошибок
-			errorResponse.Error = err.Error()
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(errorResponse)
-		}
+		writeError(w, r, err)
 		return
 	}
+	writeUserBanner(w, r, banner)
+}
 
+// requestedLocales возвращает локали-кандидаты, параметр locale важнее заголовка Accept-Language
+func requestedLocales(r *http.Request) []string {
+	requested := locale.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if tag := r.URL.Query().Get("locale"); tag != "" {
+		requested = []string{tag}
+	}
+	return locale.Candidates(requested)
+}
+
+func writeUserBanner(w http.ResponseWriter, r *http.Request, banner *models.LocalizedBanner) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Add("Vary", "Accept-Language")
+	if banner.Locale != "" {
+		w.Header().Set("Content-Language", banner.Locale)
+	}
+	// Подстановка переменных из параметров запроса
+	content := templating.Render(banner.Content, banner.Templates, templating.Vars(r.URL.Query()))
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(bannerContent)
+	json.NewEncoder(w).Encode(content)
 }
 
-func BannersGet(w http.ResponseWriter, r *http.Request) {
-	// Получение параметров запроса
-	limit, err := ValidateInt(r.URL.Query().Get("limit"))
-	if err != nil && !strings.Contains(err.Error(), "value required") {
-		http.Error(w, "Invalid limit value", http.StatusBadRequest)
-		return
-	}
-	offset, err := ValidateInt(r.URL.Query().Get("offset"))
-	if err != nil && !strings.Contains(err.Error(), "value required") {
-		http.Error(w, "Invalid offset value", http.StatusBadRequest)
+func (Handlers) BannerGet(w http.ResponseWriter, r *http.Request, params api.BannerGetParams) {
+	// Проверка наличия параметров
+	if params.FeatureId == nil && params.TagId == nil {
+		badRequest(w, r, "At least one of feature_id or tag_id must be provided")
 		return
 	}
-	featureId, featureErr := ValidateInt(r.URL.Query().Get("feature_id"))
-	tagId, tagErr := ValidateInt(r.URL.Query().Get("tag_id"))
-	// Проверка наличия параметров
-	var errorResponse models.ErrorResponse
-	if featureErr != nil && tagErr != nil {
-		errorResponse.Error = "At least one of feature_id or tag_id must be provided"
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse)
+	if params.FeatureId != nil {
+		if !authorizeFeature(w, r, rbac.ScopeBannerList, int32(*params.FeatureId)) {
+			return
+		}
+	} else if !authorizeAllFeatures(w, r, rbac.ScopeBannerList) {
 		return
 	}
 
 	// Получение баннеров из базы данных
-	banners, err := db.GetBanners(featureId, tagId, limit, offset)
+	banners, err := db.GetBanners(r.Context(), tenantFromRequest(r), params.FeatureId, params.TagId, params.Limit, params.Offset)
 	if err != nil {
-		errorResponse.Error = err.Error()
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(errorResponse)
+		writeError(w, r, err)
 		return
 	}
 	if len(banners) == 0 {
@@ -100,24 +89,30 @@ func BannersGet(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(banners)
 }
 
-func BannerPost(w http.ResponseWriter, r *http.Request) {
+func (Handlers) BannerPost(w http.ResponseWriter, r *http.Request, params api.BannerPostParams) {
 	// Получение параметров запроса
-	var banner models.BannerNoId
-	var errorResponse models.ErrorResponse
-	err := json.NewDecoder(r.Body).Decode(&banner)
+	var request api.BannerPostJSONRequestBody
+	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
-		errorResponse.Error = err.Error()
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse)
+		badRequest(w, r, err.Error())
+		return
+	}
+	banner := bannerFromRequest(request)
+	if !authorizeFeature(w, r, rbac.ScopeBannerWrite, banner.FeatureId) {
+		return
+	}
+	if !validateBannerContent(w, r, banner) {
+		return
+	}
+	if params.IdempotencyKey != nil && *params.IdempotencyKey != "" {
+		bannerPostIdempotent(w, r, *params.IdempotencyKey, request, banner)
 		return
 	}
 	var response models.IdResponse
 	// Создание баннера в базе данных
-	response.BannerId, err = db.CreateBanner(banner)
+	response.BannerId, err = db.CreateBanner(r.Context(), tenantFromRequest(r), banner, actorFromRequest(r))
 	if err != nil {
-		errorResponse.Error = err.Error()
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(errorResponse)
+		writeError(w, r, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
@@ -125,85 +120,80 @@ func BannerPost(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func BannerIdPatch(w http.ResponseWriter, r *http.Request) {
-	// Получение параметров запроса
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	var errorResponse models.ErrorResponse
-	if idStr == "" {
-		errorResponse.Error = "Banner Id is required"
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse)
+func bannerPostIdempotent(w http.ResponseWriter, r *http.Request, key string, request api.BannerPostJSONRequestBody, banner models.BannerNoId) {
+	// Хэш считается по разобранному запросу: пробелы и порядок ключей JSON на него не влияют
+	canonical, err := json.Marshal(request)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
-
-	id, err := ValidateInt(idStr)
+	hash := sha256.Sum256(canonical)
+	requestHash := hex.EncodeToString(hash[:])
+	// Создание баннера или получение ранее сохраненного ответа
+	record, created, err := db.CreateBannerIdempotent(r.Context(), tenantFromRequest(r), key, requestHash, banner, actorFromRequest(r))
 	if err != nil {
-		errorResponse.Error = "Invalid banner Id"
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse)
+		writeError(w, r, err)
 		return
 	}
+	if !created && record.RequestHash != requestHash {
+		writeErrorResponse(w, r, http.StatusUnprocessableEntity, models.ErrorCodeIdempotencyKey,
+			"Idempotency-Key was already used with a different request body", nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if !created {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Response)
+}
 
-	var banner models.BannerNoId
-	err = json.NewDecoder(r.Body).Decode(&banner)
+func (Handlers) BannerIdPatch(w http.ResponseWriter, r *http.Request, id int, params api.BannerIdPatchParams) {
+	// Получение параметров запроса
+	var patch api.BannerIdPatchJSONRequestBody
+	err := json.NewDecoder(r.Body).Decode(&patch)
 	if err != nil {
-		errorResponse.Error = err.Error()
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse)
+		badRequest(w, r, err.Error())
 		return
 	}
 
-	exist, _ := db.BannerExists(*id)
-	if !exist {
-		w.WriteHeader(http.StatusNotFound)
+	// Нужны права и на текущую фичу баннера, и на новую
+	if !authorizeBanner(w, r, id, rbac.ScopeBannerWrite) {
 		return
 	}
-
-	// Обновление баннера в базе данных
-	err = db.UpdateBanner(*id, banner)
+	// Переданные поля накладываются на последнюю ревизию, остальные не меняются
+	current, err := db.GetBannerPreview(r.Context(), tenantFromRequest(r), id)
 	if err != nil {
-		errorResponse.Error = err.Error()
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(errorResponse)
+		writeError(w, r, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
-}
-
-func BannerIdDelete(w http.ResponseWriter, r *http.Request) {
-	// Получение параметров запроса
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	var errorResponse models.ErrorResponse
-	if idStr == "" {
-		errorResponse.Error = "Banner Id is required"
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse)
+	banner := applyBannerPatch(*current, patch)
+	if !authorizeFeature(w, r, rbac.ScopeBannerWrite, banner.FeatureId) {
+		return
+	}
+	if !validateBannerContent(w, r, banner) {
 		return
 	}
 
-	id, err := ValidateInt(idStr)
+	// Изменения сохраняются в черновик и не видны пользователям до публикации
+	err = db.UpdateBanner(r.Context(), tenantFromRequest(r), id, banner, actorFromRequest(r))
 	if err != nil {
-		errorResponse.Error = "Invalid banner Id"
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse)
+		writeError(w, r, err)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+}
 
-	exist, _ := db.BannerExists(*id)
-	if !exist {
-		w.WriteHeader(http.StatusNotFound)
+func (Handlers) BannerIdDelete(w http.ResponseWriter, r *http.Request, id int, params api.BannerIdDeleteParams) {
+	if !authorizeBanner(w, r, id, rbac.ScopeBannerDelete) {
 		return
 	}
 
 	// Удаление баннера из базы данных
-	err = db.DeleteBanner(*id)
+	err := db.DeleteBanner(r.Context(), tenantFromRequest(r), id, actorFromRequest(r))
 	if err != nil {
-		errorResponse.Error = err.Error()
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(errorResponse)
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
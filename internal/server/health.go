@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"my_app/internal/cache"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+	// Без необязательной зависимости сервис работает, но медленнее
+	optional bool
+}
+
+// HealthDependencies - проверки зависимостей, которые выполняет Readyz
+type HealthDependencies struct {
+	Database   func(ctx context.Context) error
+	Migrations func(ctx context.Context) error
+	Cache      func(ctx context.Context) error
+}
+
+// DefaultHealthDependencies проверяет подключения пакетов db и cache
+var DefaultHealthDependencies = HealthDependencies{
+	Database:   db.Ping,
+	Migrations: db.CheckMigrations,
+	Cache:      cache.Ping,
+}
+
+var healthChecks []healthCheck
+
+func init() {
+	InitHealth(DefaultHealthDependencies)
+}
+
+// InitHealth заменяет проверки Readyz, по умолчанию DefaultHealthDependencies
+func InitHealth(deps HealthDependencies) {
+	healthChecks = []healthCheck{
+		{name: "database", check: deps.Database},
+		{name: "migrations", check: deps.Migrations},
+		{name: "cache", check: deps.Cache, optional: true},
+	}
+}
+
+// Healthz отвечает, пока процесс жив, зависимости не проверяются
+func (Handlers) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.HealthResponse{Status: models.HealthOK})
+}
+
+// Readyz параллельно проверяет зависимости. Недоступный кэш переводит сервис
+// в degraded, но не снимает его с балансировки: баннеры берутся из базы данных
+func (Handlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	response := models.HealthResponse{
+		Status: models.HealthOK,
+		Checks: make(map[string]models.HealthCheck, len(healthChecks)),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, hc := range healthChecks {
+		wg.Add(1)
+		go func(hc healthCheck) {
+			defer wg.Done()
+			result := runHealthCheck(r.Context(), hc)
+			mu.Lock()
+			response.Checks[hc.name] = result
+			response.Status = worseHealth(response.Status, result.Status)
+			mu.Unlock()
+		}(hc)
+	}
+	wg.Wait()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if response.Status == models.HealthFail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func runHealthCheck(ctx context.Context, hc healthCheck) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, serverCfg.HealthTimeout)
+	defer cancel()
+	start := time.Now()
+	err := hc.check(ctx)
+	result := models.HealthCheck{
+		Status:    models.HealthOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.Status = models.HealthFail
+		if hc.optional {
+			result.Status = models.HealthDegraded
+		}
+	}
+	return result
+}
+
+var healthOrder = map[string]int{models.HealthOK: 0, models.HealthDegraded: 1, models.HealthFail: 2}
+
+func worseHealth(a, b string) string {
+	if healthOrder[b] > healthOrder[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"my_app/internal/background"
+	"my_app/internal/config"
+	"net"
+	"net/http"
+	"time"
+)
+
+var serverCfg = config.Server{
+	ReadTimeout:      10 * time.Second,
+	WriteTimeout:     30 * time.Second,
+	IdleTimeout:      2 * time.Minute,
+	ShutdownTimeout:  30 * time.Second,
+	HealthTimeout:    2 * time.Second,
+	ValidateRequests: true,
+}
+
+var features = config.Features{Preview: true, Bulk: true, APIKeys: true, Metrics: true}
+
+// InitServer задает таймауты сервера и включенные группы маршрутов,
+// вызывается до NewRouter
+func InitServer(cfg config.Server, enabled config.Features) {
+	serverCfg = cfg
+	features = enabled
+}
+
+// Serve обрабатывает запросы, пока не отменен ctx, затем перестает принимать соединения,
+// дожидается текущих запросов и фоновых задач, но не дольше ShutdownTimeout
+func Serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: serverCfg.ReadTimeout,
+		ReadTimeout:       serverCfg.ReadTimeout,
+		WriteTimeout:      serverCfg.WriteTimeout,
+		IdleTimeout:       serverCfg.IdleTimeout,
+	}
+	errs := make(chan error, 1)
+	go func() {
+		if serverCfg.TLSCertFile != "" {
+			errs <- srv.ServeTLS(listener, serverCfg.TLSCertFile, serverCfg.TLSKeyFile)
+			return
+		}
+		errs <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down server", "timeout", serverCfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverCfg.ShutdownTimeout)
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	if err != nil {
+		// Не завершившиеся запросы обрываются
+		srv.Close()
+		return err
+	}
+	if err = <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return background.Wait(shutdownCtx)
+}
@@ -1,23 +1,59 @@
 package server
 
 import (
-	"log"
+	"log/slog"
+	"my_app/internal/logging"
+	"my_app/internal/templating"
 	"net/http"
+	"net/url"
 	"time"
 )
 
+const RequestIDHeader = "X-Request-ID"
+
+// Logger присваивает запросу идентификатор из X-Request-ID или новый, возвращает его
+// в ответе и пишет строку лога по завершении запроса
 func Logger(inner http.Handler, name string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		id := r.Header.Get(RequestIDHeader)
+		if !logging.ValidRequestID(id) {
+			id = logging.NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := logging.WithRequestID(r.Context(), id)
+		recorder := &statusRecorder{ResponseWriter: w}
 
-		inner.ServeHTTP(w, r)
+		inner.ServeHTTP(recorder, r.WithContext(ctx))
 
-		log.Printf(
-			"%s %s %s %s",
-			r.Method,
-			r.RequestURI,
-			name,
-			time.Since(start),
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "request",
+			"method", r.Method,
+			"uri", logURI(r.URL),
+			"route", name,
+			"status", recorder.status,
+			"duration", time.Since(start),
 		)
 	})
 }
+
+// logURI скрывает в строке запроса токен предпросмотра, который действует как пароль,
+// и переменные шаблонов: в них приходят данные пользователя
+func logURI(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+	query := u.Query()
+	for name := range query {
+		if name == "preview_token" || templating.IsVar(name) {
+			query[name] = []string{"redacted"}
+		}
+	}
+	return u.Path + "?" + query.Encode()
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"my_app/internal/metrics"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// statusRecorder запоминает код ответа и сохраняет потоковую отдачу через http.Flusher
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap дает http.ResponseController доступ к исходному ResponseWriter
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Instrument считает запросы и их длительность по маршруту и коду ответа,
+// включая отклоненные авторизацией и лимитом запросов
+func Instrument(inner http.Handler, name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		inner.ServeHTTP(recorder, r)
+
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		status := strconv.Itoa(recorder.status)
+		metrics.HTTPRequests.WithLabelValues(name, r.Method, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(name, r.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+var metricsHandler = metrics.Handler()
+
+func (Handlers) Metrics(w http.ResponseWriter, r *http.Request) {
+	metricsHandler.ServeHTTP(w, r)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"my_app/api"
+	"my_app/internal/auth"
+	"my_app/internal/db"
+	"my_app/internal/locale"
+	"my_app/internal/models"
+	"my_app/internal/preview"
+	"my_app/internal/rbac"
+	"net/http"
+	"time"
+)
+
+func (Handlers) BannerPreviewPost(w http.ResponseWriter, r *http.Request, id int, params api.BannerPreviewPostParams) {
+	// Получение параметров запроса
+	var request api.BannerPreviewPostJSONRequestBody
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil && err != io.EOF {
+		badRequest(w, r, err.Error())
+		return
+	}
+	ttl := preview.DefaultTTL
+	if request.Ttl != nil && *request.Ttl != "" {
+		ttl, err = time.ParseDuration(*request.Ttl)
+		if err != nil || ttl <= 0 || ttl > preview.MaxTTL {
+			badRequest(w, r, "ttl must be a positive duration not greater than "+preview.MaxTTL.String())
+			return
+		}
+	}
+
+	if !authorizeBanner(w, r, id, rbac.ScopeBannerPreview) {
+		return
+	}
+
+	// Выпуск подписанного токена предпросмотра
+	var response models.PreviewResponse
+	response.Token, response.ExpiresAt, err = preview.Sign(tenantFromRequest(r), int32(id), ttl)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func userBannerPreview(w http.ResponseWriter, r *http.Request, token string) {
+	tenant, bannerId, err := preview.Verify(token)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusForbidden, models.ErrorCodeForbidden, err.Error(), nil)
+		return
+	}
+
+	// Последняя ревизия читается из базы данных в обход кэша и флага активности
+	// Ссылки, выпущенные до появления тенантов, относятся к тенанту по умолчанию
+	if tenant == "" {
+		tenant = auth.DefaultTenant
+	}
+	dbBanner, err := db.GetBannerPreview(r.Context(), tenant, int(bannerId))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	banner, ok := locale.Localize(dbBanner, requestedLocales(r))
+	if !ok {
+		writeError(w, r, db.ErrNoLocale)
+		return
+	}
+	db.ParseTemplates(r.Context(), banner)
+	// Предпросмотр не должен оседать в общих кэшах
+	w.Header().Set("Cache-Control", "no-store")
+	writeUserBanner(w, r, banner)
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"log/slog"
+	"math"
+	"my_app/internal/models"
+	"my_app/internal/ratelimit"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// RateLimitMiddleware ограничивает частоту запросов клиента к маршруту. На защищенных
+// маршрутах стоит после авторизации и считает запросы по проверенному токену или ключу,
+// неудачные попытки авторизации AuthMiddleware считает по адресу клиента
+func RateLimitMiddleware(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !allowRequest(w, r, route, rateLimitClient(r)) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allowRequest учитывает запрос клиента и при превышении лимита сам пишет ответ 429
+func allowRequest(w http.ResponseWriter, r *http.Request, route string, client string) bool {
+	allowed, retryAfter, err := ratelimit.Allow(r.Context(), route, client)
+	if err != nil {
+		// Недоступность хранилища лимитов не должна останавливать сервис
+		slog.WarnContext(r.Context(), "rate limit check failed", "route", route, "error", err)
+		return true
+	}
+	if !allowed {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		writeErrorResponse(w, r, http.StatusTooManyRequests, models.ErrorCodeRateLimited, "rate limit exceeded", nil)
+		return false
+	}
+	return true
+}
+
+// rateLimitClient - тенант и subject проверенного токена или ключа, без них - адрес клиента.
+// Непроверенный токен клиента не определяет: новый токен на каждый запрос обходил бы лимит
+func rateLimitClient(r *http.Request) string {
+	if principal := principalFromRequest(r); principal != nil && principal.Subject != "" {
+		return "principal:" + principal.Tenant + ":" + principal.Subject
+	}
+	return clientAddr(r)
+}
+
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -1,88 +1,180 @@
 package server
 
 import (
-	"fmt"
+	"errors"
+	"log"
+	"my_app/api"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
 	"net/http"
-	"strings"
 
 	"github.com/gorilla/mux"
 )
 
 type Route struct {
-	Name        string
-	Method      string
-	Pattern     string
-	HandlerFunc http.HandlerFunc
+	// operationId из api/api.yaml
+	Name    string
+	Method  string
+	Pattern string
+	Handler http.Handler
+	// Scope, необходимый для доступа, пустой для открытых маршрутов
+	Scope rbac.Scope
 }
 
-type Routes []Route
+// Handlers реализует api.ServerInterface, сгенерированный из api/api.yaml.
+// Если спецификация и обработчики расходятся, пакет не собирается
+type Handlers struct{}
 
+var _ api.ServerInterface = Handlers{}
+
+// Wrapper разбирает параметры пути и запроса по спецификации и вызывает Handlers
+var Wrapper = api.ServerInterfaceWrapper{
+	Handler:          Handlers{},
+	ErrorHandlerFunc: paramError,
+}
+
+// routeScopes - права на операции спецификации, операции без security: [] без них не регистрируются
+var routeScopes = map[string]rbac.Scope{
+	"UserBannerGet":         rbac.ScopeBannerRead,
+	"BannerGet":             rbac.ScopeBannerList,
+	"BannerPost":            rbac.ScopeBannerWrite,
+	"BannerExport":          rbac.ScopeBannerList,
+	"BannerImport":          rbac.ScopeBannerWrite,
+	"BannerIdPatch":         rbac.ScopeBannerWrite,
+	"BannerIdDelete":        rbac.ScopeBannerDelete,
+	"BannerTransitionPost":  rbac.ScopeBannerList,
+	"BannerHistoryGet":      rbac.ScopeBannerList,
+	"BannerPreviewPost":     rbac.ScopeBannerPreview,
+	"FeatureSchemaGet":      rbac.ScopeSchemaWrite,
+	"FeatureSchemaPut":      rbac.ScopeSchemaWrite,
+	"FeatureSchemaDelete":   rbac.ScopeSchemaWrite,
+	"FeatureSchemaValidate": rbac.ScopeSchemaWrite,
+	"AuditGet":              rbac.ScopeAuditRead,
+	"APIKeysGet":            rbac.ScopeAPIKeyManage,
+	"APIKeyPost":            rbac.ScopeAPIKeyManage,
+	"APIKeyRotatePost":      rbac.ScopeAPIKeyManage,
+	"APIKeyDelete":          rbac.ScopeAPIKeyManage,
+}
+
+// Пробы оркестратора часты и не должны упираться в лимиты или засорять логи
+var probes = map[string]bool{
+	"Healthz": true,
+	"Readyz":  true,
+}
+
+// NewRouter регистрирует операции api/api.yaml сгенерированным кодом
+// и оборачивает каждую в middleware по ее описанию в спецификации
 func NewRouter() *mux.Router {
-	router := mux.NewRouter().StrictSlash(true)
-	for _, route := range routes {
-		var handler http.Handler
-		handler = route.HandlerFunc
-		handler = Logger(handler, route.Name)
-		switch route.Name {
-		case "UserBannerGet":
-			handler = AuthMiddleware(userOrAdminAccessCheck)(handler)
-		case "BannerGet", "BannerPost", "BannerIdDelete", "BannerIdPatch":
-			handler = AuthMiddleware(adminAccessCheck)(handler)
+	router := mux.NewRouter()
+	api.HandlerWithOptions(Handlers{}, api.GorillaServerOptions{
+		BaseRouter:       router,
+		ErrorHandlerFunc: paramError,
+	})
+	operations := specRoutes()
+	err := router.Walk(func(muxRoute *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		pattern, err := muxRoute.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := muxRoute.GetMethods()
+		if err != nil {
+			return err
+		}
+		route, ok := operations[methods[0]+" "+pattern]
+		if !ok {
+			return errors.New("operation " + methods[0] + " " + pattern + " is not described in api spec")
 		}
-		router.
-			Methods(route.Method).
-			Path(route.Pattern).
-			Name(route.Name).
-			Handler(handler)
+		route.Handler = muxRoute.GetHandler()
+		muxRoute.Name(route.Name).Handler(routeHandler(route))
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("router: %v", err)
 	}
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notFound(w, r, "route not found")
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeErrorResponse(w, r, http.StatusMethodNotAllowed, models.ErrorCodeMethodNotAllowed, "method not allowed", nil)
+	})
 
 	return router
 }
 
-func Index(w http.ResponseWriter, r *http.Request) {
-	fmt.Fprintf(w, "Hello World!")
+func routeHandler(route Route) http.Handler {
+	if !routeEnabled(route.Name) {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			notFound(w, r, "route not found")
+		})
+	}
+	if probes[route.Name] {
+		return Instrument(route.Handler, route.Name)
+	}
+	handler := route.Handler
+	handler = ValidateMiddleware(route)(handler)
+	handler = RateLimitMiddleware(route.Name)(handler)
+	if route.Scope != "" {
+		handler = AuthMiddleware(route.Name, scopeAccessCheck(route.Scope))(handler)
+	}
+	handler = Instrument(handler, route.Name)
+	handler = Logger(handler, route.Name)
+	handler = Trace(handler, route)
+	return handler
 }
 
-var routes = Routes{
-	Route{
-		"Index",
-		"GET",
-		"/",
-		Index,
-	},
-
-	Route{
-		"BannerGet",
-		strings.ToUpper("Get"),
-		"/banner",
-		BannersGet,
-	},
-
-	Route{
-		"BannerIdDelete",
-		strings.ToUpper("Delete"),
-		"/banner/{id}",
-		BannerIdDelete,
-	},
-
-	Route{
-		"BannerIdPatch",
-		strings.ToUpper("Patch"),
-		"/banner/{id}",
-		BannerIdPatch,
-	},
+// specRoutes описывает операции спецификации по методу и шаблону пути. Защищенная операция
+// без записи в routeScopes или запись без операции не дают запустить сервис
+func specRoutes() map[string]Route {
+	doc := loadSpec()
+	routes := make(map[string]Route)
+	described := make(map[string]bool, len(routeScopes))
+	for pattern, pathItem := range doc.Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			route := Route{
+				Name:    operation.OperationID,
+				Method:  method,
+				Pattern: pattern,
+				Scope:   routeScopes[operation.OperationID],
+			}
+			// Без security в операции действует общая для спецификации
+			secured := operation.Security == nil || len(*operation.Security) > 0
+			if secured && route.Scope == "" {
+				log.Fatalf("router: operation %s has no scope", route.Name)
+			}
+			described[route.Name] = true
+			routes[method+" "+pattern] = route
+		}
+	}
+	for name := range routeScopes {
+		if !described[name] {
+			log.Fatalf("router: scope for unknown operation %s", name)
+		}
+	}
+	return routes
+}
 
-	Route{
-		"BannerPost",
-		strings.ToUpper("Post"),
-		"/banner",
-		BannerPost,
-	},
+// routeEnabled скрывает маршруты выключенных в features групп, на них отвечает 404
+func routeEnabled(name string) bool {
+	switch name {
+	case "BannerPreviewPost":
+		return features.Preview
+	case "BannerExport", "BannerImport":
+		return features.Bulk
+	case "APIKeysGet", "APIKeyPost", "APIKeyRotatePost", "APIKeyDelete":
+		return features.APIKeys
+	case "Metrics":
+		return features.Metrics
+	}
+	return true
+}
 
-	Route{
-		"UserBannerGet",
-		strings.ToUpper("Get"),
-		"/user_banner",
-		UserBannerGet,
-	},
+// paramError отвечает на параметры, которые не удалось разобрать по спецификации.
+// Обычно такие запросы раньше отклоняет ValidateMiddleware
+func paramError(w http.ResponseWriter, r *http.Request, err error) {
+	var invalid *api.InvalidParamFormatError
+	if errors.As(err, &invalid) {
+		badRequest(w, r, "Invalid "+invalid.ParamName+" value")
+		return
+	}
+	badRequest(w, r, err.Error())
 }
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"my_app/api"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/schema"
+	"my_app/internal/templating"
+	"net/http"
+)
+
+const maxSchemaSize = 1 << 20
+
+func featureSchema(r *http.Request, featureId int32) (*schema.Schema, error) {
+	featureSchema, err := db.GetFeatureSchema(r.Context(), tenantFromRequest(r), int(featureId))
+	if err != nil || featureSchema == nil {
+		return nil, err
+	}
+	return schema.Compile(featureSchema.Schema)
+}
+
+// validateBannerContent проверяет шаблоны и content по схеме фичи и при ошибке сам пишет ответ
+func validateBannerContent(w http.ResponseWriter, r *http.Request, banner models.BannerNoId) bool {
+	_, fields := templating.ParseContent(banner.Content)
+	if len(fields) > 0 {
+		writeErrorResponse(w, r, http.StatusBadRequest, models.ErrorCodeValidation, "content contains invalid templates", fields)
+		return false
+	}
+	contentSchema, err := featureSchema(r, banner.FeatureId)
+	if err != nil {
+		writeError(w, r, err)
+		return false
+	}
+	if contentSchema == nil {
+		return true
+	}
+	fields, err = schema.ValidateContent(contentSchema, banner.Content)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return false
+	}
+	if len(fields) > 0 {
+		writeErrorResponse(w, r, http.StatusBadRequest, models.ErrorCodeValidation, "content does not match feature schema", fields)
+		return false
+	}
+	return true
+}
+
+func (Handlers) FeatureSchemaGet(w http.ResponseWriter, r *http.Request, featureId int, params api.FeatureSchemaGetParams) {
+	if !authorizeFeature(w, r, rbac.ScopeSchemaWrite, int32(featureId)) {
+		return
+	}
+	featureSchema, err := db.GetFeatureSchema(r.Context(), tenantFromRequest(r), featureId)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if featureSchema == nil {
+		notFound(w, r, "feature has no content schema")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(featureSchema)
+}
+
+func (Handlers) FeatureSchemaPut(w http.ResponseWriter, r *http.Request, featureId int, params api.FeatureSchemaPutParams) {
+	if !authorizeFeature(w, r, rbac.ScopeSchemaWrite, int32(featureId)) {
+		return
+	}
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSchemaSize))
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+	// Схема должна компилироваться до сохранения
+	_, err = schema.Compile(raw)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	err = db.SetFeatureSchema(r.Context(), tenantFromRequest(r), featureId, raw)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (Handlers) FeatureSchemaDelete(w http.ResponseWriter, r *http.Request, featureId int, params api.FeatureSchemaDeleteParams) {
+	if !authorizeFeature(w, r, rbac.ScopeSchemaWrite, int32(featureId)) {
+		return
+	}
+	deleted, err := db.DeleteFeatureSchema(r.Context(), tenantFromRequest(r), featureId)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if !deleted {
+		notFound(w, r, "feature has no content schema")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (Handlers) FeatureSchemaValidate(w http.ResponseWriter, r *http.Request, featureId int, params api.FeatureSchemaValidateParams) {
+	if !authorizeFeature(w, r, rbac.ScopeSchemaWrite, int32(featureId)) {
+		return
+	}
+	contentSchema, err := featureSchema(r, int32(featureId))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if contentSchema == nil {
+		notFound(w, r, "feature has no content schema")
+		return
+	}
+
+	// Проверка всех баннеров фичи по текущей схеме
+	report := models.SchemaValidationReport{
+		FeatureId: int32(featureId),
+		Invalid:   []models.InvalidBanner{},
+	}
+	err = db.ExportBanners(r.Context(), tenantFromRequest(r), &featureId, nil, func(banner models.BannerExpanded) error {
+		report.Checked++
+		fields, err := schema.ValidateContent(contentSchema, banner.Content)
+		if err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			report.Invalid = append(report.Invalid, models.InvalidBanner{BannerId: banner.ID, Fields: fields})
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
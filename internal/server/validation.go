@@ -0,0 +1,219 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"log/slog"
+	"my_app/api"
+	"my_app/internal/models"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gorilla/mux"
+)
+
+var (
+	specOnce sync.Once
+	spec     *openapi3.T
+)
+
+func init() {
+	// Импорт принимает NDJSON, содержимое строк проверяет обработчик
+	openapi3filter.RegisterBodyDecoder("application/x-ndjson", func(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (any, error) {
+		data, err := io.ReadAll(body)
+		return string(data), err
+	})
+}
+
+// loadSpec разбирает api/api.yaml один раз, ошибка в спецификации не дает запустить сервис
+func loadSpec() *openapi3.T {
+	specOnce.Do(func() {
+		loader := openapi3.NewLoader()
+		doc, err := loader.LoadFromData(api.Spec)
+		if err != nil {
+			log.Fatalf("api spec: %v", err)
+		}
+		err = doc.Validate(context.Background())
+		if err != nil {
+			log.Fatalf("api spec: %v", err)
+		}
+		spec = doc
+	})
+	return spec
+}
+
+var (
+	// Авторизацию проверяет AuthMiddleware, значения по умолчанию в запрос не подставляются
+	requestValidation = openapi3filter.Options{
+		MultiError:          true,
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults: true,
+	}
+	responseValidation = openapi3filter.Options{
+		MultiError:            true,
+		IncludeResponseStatus: true,
+	}
+)
+
+// Тела запросов читаются проверкой целиком, поэтому их размер ограничивается до нее:
+// для импорта и схем - как в обработчиках, для остальных маршрутов - maxBodySize
+const maxBodySize = 1 << 20
+
+var bodyLimits = map[string]int64{
+	"BannerImport":     maxImportSize,
+	"FeatureSchemaPut": maxSchemaSize,
+}
+
+// Потоковые ответы не буферизуются для проверки, иначе выгрузка целиком оседала бы в памяти
+var streamingRoutes = map[string]bool{
+	"BannerExport": true,
+}
+
+// specRoute находит операцию маршрута в спецификации, nil - маршрут в ней не описан
+func specRoute(route Route) *routers.Route {
+	doc := loadSpec()
+	pathItem := doc.Paths.Value(route.Pattern)
+	if pathItem == nil {
+		return nil
+	}
+	operation := pathItem.GetOperation(route.Method)
+	if operation == nil {
+		return nil
+	}
+	return &routers.Route{
+		Spec:      doc,
+		Path:      route.Pattern,
+		PathItem:  pathItem,
+		Method:    route.Method,
+		Operation: operation,
+	}
+}
+
+// ValidateMiddleware отклоняет запросы, не соответствующие api/api.yaml, с перечнем ошибок по полям.
+// Если включена проверка ответов, ответ (кроме потоковых) буферизуется и при расхождении со спецификацией
+// заменяется на 500, так в тестах обнаруживается дрейф спецификации
+func ValidateMiddleware(route Route) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !serverCfg.ValidateRequests && !serverCfg.ValidateResponses {
+			return next
+		}
+		operation := specRoute(route)
+		if operation == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: mux.Vars(r),
+				Route:      operation,
+				Options:    &requestValidation,
+			}
+			if serverCfg.ValidateRequests {
+				limit, ok := bodyLimits[route.Name]
+				if !ok {
+					limit = maxBodySize
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+				err := openapi3filter.ValidateRequest(r.Context(), input)
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					badRequest(w, r, tooLarge.Error())
+					return
+				}
+				if err != nil {
+					writeErrorResponse(w, r, http.StatusBadRequest, models.ErrorCodeValidation,
+						"request does not match api spec", specFieldErrors(err, ""))
+					return
+				}
+			}
+			if !serverCfg.ValidateResponses || streamingRoutes[route.Name] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buffer := &responseBuffer{ResponseWriter: w}
+			next.ServeHTTP(buffer, r)
+			if buffer.status == 0 {
+				buffer.status = http.StatusOK
+			}
+			err := openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 buffer.status,
+				Header:                 w.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(buffer.body.Bytes())),
+				Options:                &responseValidation,
+			})
+			if err != nil {
+				slog.ErrorContext(r.Context(), "response does not match api spec", "route", route.Name, "status", buffer.status, "error", err)
+				writeErrorResponse(w, r, http.StatusInternalServerError, models.ErrorCodeInternal,
+					"response does not match api spec", specFieldErrors(err, ""))
+				return
+			}
+			w.WriteHeader(buffer.status)
+			w.Write(buffer.body.Bytes())
+		})
+	}
+}
+
+// specFieldErrors раскладывает ошибки проверки по полям: для тела - JSON Pointer,
+// для параметров - имя параметра
+func specFieldErrors(err error, field string) []models.FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var fields []models.FieldError
+		for _, inner := range e {
+			fields = append(fields, specFieldErrors(inner, field)...)
+		}
+		return fields
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			field = e.Parameter.Name
+		}
+		if e.Err == nil {
+			return []models.FieldError{{Field: field, Error: e.Reason}}
+		}
+		return specFieldErrors(e.Err, field)
+	case *openapi3filter.ResponseError:
+		if e.Err == nil {
+			return []models.FieldError{{Field: field, Error: e.Reason}}
+		}
+		return specFieldErrors(e.Err, field)
+	case *openapi3.SchemaError:
+		if pointer := e.JSONPointer(); len(pointer) > 0 {
+			field += "/" + strings.Join(pointer, "/")
+		}
+		return []models.FieldError{{Field: field, Error: e.Reason}}
+	}
+	return []models.FieldError{{Field: field, Error: err.Error()}}
+}
+
+// responseBuffer задерживает ответ до проверки по спецификации
+type responseBuffer struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *responseBuffer) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+// Flush ничего не отправляет, ответ уходит после проверки
+func (b *responseBuffer) Flush() {}
+
+func (b *responseBuffer) Unwrap() http.ResponseWriter {
+	return b.ResponseWriter
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"my_app/api"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"net/http"
+)
+
+// Редактор готовит ревизию, утверждает и публикует ее согласующий
+var transitionScopes = map[models.TransitionAction]rbac.Scope{
+	models.ActionSubmit:   rbac.ScopeBannerWrite,
+	models.ActionWithdraw: rbac.ScopeBannerWrite,
+	models.ActionApprove:  rbac.ScopeBannerApprove,
+	models.ActionReject:   rbac.ScopeBannerApprove,
+	models.ActionPublish:  rbac.ScopeBannerPublish,
+}
+
+// authorizeBanner проверяет scope для всех фич баннера, отвечает 404 если баннера нет
+func authorizeBanner(w http.ResponseWriter, r *http.Request, id int, scope rbac.Scope) bool {
+	featureIds, err := db.GetBannerFeatureIds(r.Context(), tenantFromRequest(r), id)
+	if err != nil {
+		writeError(w, r, err)
+		return false
+	}
+	return authorizeFeature(w, r, scope, featureIds...)
+}
+
+func (Handlers) BannerTransitionPost(w http.ResponseWriter, r *http.Request, id int, params api.BannerTransitionPostParams) {
+	// Получение параметров запроса
+	var request api.BannerTransitionPostJSONRequestBody
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+	action := models.TransitionAction(request.Action)
+	var comment string
+	if request.Comment != nil {
+		comment = *request.Comment
+	}
+	scope, ok := transitionScopes[action]
+	if !ok {
+		writeError(w, r, db.ErrUnknownTransition)
+		return
+	}
+	if !authorizeBanner(w, r, id, scope) {
+		return
+	}
+
+	// Переход ревизии в новый статус
+	revision, err := db.TransitionBanner(r.Context(), tenantFromRequest(r), id, action, actorFromRequest(r), comment)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revision)
+}
+
+func (Handlers) BannerHistoryGet(w http.ResponseWriter, r *http.Request, id int, params api.BannerHistoryGetParams) {
+	if !authorizeBanner(w, r, id, rbac.ScopeBannerList) {
+		return
+	}
+
+	history, err := db.GetBannerHistory(r.Context(), tenantFromRequest(r), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
@@ -0,0 +1,238 @@
+package templating
+
+import (
+	"fmt"
+	"my_app/internal/config"
+	"my_app/internal/models"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Синтаксис: {{name}} или {{name|значение по умолчанию}}.
+// Других конструкций нет, поэтому рендеринг не может выполнить произвольный код.
+const (
+	openDelim  = "{{"
+	closeDelim = "}}"
+
+	MaxValueLength = 256
+)
+
+var varName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+var allowedVars map[string]bool
+
+func InitTemplating(cfg config.Templating) {
+	allowedVars = make(map[string]bool)
+	for _, name := range cfg.Vars {
+		if name = strings.TrimSpace(name); name != "" {
+			allowedVars[name] = true
+		}
+	}
+}
+
+// IsVar сообщает, что параметр запроса с таким именем подставляется в шаблоны
+func IsVar(name string) bool {
+	return allowedVars[name]
+}
+
+func AllowedVars() []string {
+	names := make([]string, 0, len(allowedVars))
+	for name := range allowedVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Parse(s string) ([]models.TemplatePart, error) {
+	var parts []models.TemplatePart
+	for s != "" {
+		start := strings.Index(s, openDelim)
+		if start < 0 {
+			if strings.Contains(s, closeDelim) {
+				return nil, fmt.Errorf("unexpected %q", closeDelim)
+			}
+			parts = append(parts, models.TemplatePart{Text: s})
+			break
+		}
+		if start > 0 {
+			if strings.Contains(s[:start], closeDelim) {
+				return nil, fmt.Errorf("unexpected %q", closeDelim)
+			}
+			parts = append(parts, models.TemplatePart{Text: s[:start]})
+		}
+		s = s[start+len(openDelim):]
+		end := strings.Index(s, closeDelim)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed %q", openDelim)
+		}
+		name, def, _ := strings.Cut(s[:end], "|")
+		name = strings.TrimSpace(name)
+		if !varName.MatchString(name) {
+			return nil, fmt.Errorf("invalid variable name %q", name)
+		}
+		if strings.Contains(def, openDelim) {
+			return nil, fmt.Errorf("nested %q in default of %q", openDelim, name)
+		}
+		parts = append(parts, models.TemplatePart{Var: name, Default: def})
+		s = s[end+len(closeDelim):]
+	}
+	return parts, nil
+}
+
+// ParseContent разбирает все строки content с переменными.
+// Ошибки синтаксиса и переменные вне TEMPLATE_VARS возвращаются как ошибки полей.
+func ParseContent(content models.ModelMap) ([]models.TemplateField, []models.FieldError) {
+	var fields []models.TemplateField
+	var fieldErrors []models.FieldError
+	var walk func(value interface{}, path []string)
+	walk = func(value interface{}, path []string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key], appendPath(path, key))
+			}
+		case models.ModelMap:
+			walk(map[string]interface{}(v), path)
+		case []interface{}:
+			for i, item := range v {
+				walk(item, appendPath(path, strconv.Itoa(i)))
+			}
+		case string:
+			if !strings.Contains(v, openDelim) && !strings.Contains(v, closeDelim) {
+				return
+			}
+			parts, err := Parse(v)
+			if err == nil {
+				for _, part := range parts {
+					if part.Var != "" && !allowedVars[part.Var] {
+						err = fmt.Errorf("variable %q is not allowed", part.Var)
+						break
+					}
+				}
+			}
+			if err != nil {
+				fieldErrors = append(fieldErrors, models.FieldError{Field: pointer(path), Error: err.Error()})
+				return
+			}
+			fields = append(fields, models.TemplateField{Path: path, Parts: parts})
+		}
+	}
+	walk(content, nil)
+	return fields, fieldErrors
+}
+
+// Render возвращает копию content с подставленными значениями, content не изменяется
+func Render(content models.ModelMap, fields []models.TemplateField, vars map[string]string) models.ModelMap {
+	if len(fields) == 0 {
+		return content
+	}
+	rendered := deepCopy(map[string]interface{}(content)).(map[string]interface{})
+	for _, field := range fields {
+		var b strings.Builder
+		for _, part := range field.Parts {
+			if part.Var == "" {
+				b.WriteString(part.Text)
+				continue
+			}
+			value, ok := vars[part.Var]
+			if !ok || value == "" {
+				value = part.Default
+			}
+			b.WriteString(value)
+		}
+		set(rendered, field.Path, b.String())
+	}
+	return rendered
+}
+
+// Vars собирает значения разрешенных переменных из параметров запроса
+func Vars(query map[string][]string) map[string]string {
+	vars := make(map[string]string)
+	for name := range allowedVars {
+		values, ok := query[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+		value := []rune(values[0])
+		if len(value) > MaxValueLength {
+			value = value[:MaxValueLength]
+		}
+		vars[name] = string(value)
+	}
+	return vars
+}
+
+func appendPath(path []string, key string) []string {
+	result := make([]string, len(path), len(path)+1)
+	copy(result, path)
+	return append(result, key)
+}
+
+func pointer(path []string) string {
+	var b strings.Builder
+	b.WriteString("/content")
+	for _, key := range path {
+		b.WriteString("/")
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = deepCopy(item)
+		}
+		return result
+	case models.ModelMap:
+		return deepCopy(map[string]interface{}(v))
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = deepCopy(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func set(root interface{}, path []string, value string) {
+	if len(path) == 0 {
+		return
+	}
+	current := root
+	for i, key := range path {
+		last := i == len(path)-1
+		switch v := current.(type) {
+		case map[string]interface{}:
+			if last {
+				v[key] = value
+				return
+			}
+			current = v[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return
+			}
+			if last {
+				v[index] = value
+				return
+			}
+			current = v[index]
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"my_app/internal/config"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+
+	defaultServiceName = "banner-service"
+)
+
+var provider *sdktrace.TracerProvider
+var traceFile *os.File
+
+// InitTracing настраивает экспорт спанов: otlp (адрес из cfg.Endpoint), stdout или file (cfg.File).
+// По умолчанию спаны не экспортируются, но W3C trace context из входящих запросов все равно передается дальше
+func InitTracing(cfg config.Tracing) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch name := cfg.Exporter; name {
+	case "", ExporterNone:
+		return
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		var writer io.Writer
+		writer, err = openTraceFile(cfg.File)
+		if err == nil {
+			exporter, err = stdouttrace.New(stdouttrace.WithWriter(writer))
+		}
+	default:
+		log.Fatalf("traces exporter must be one of %s, %s, %s, %s, got %q",
+			ExporterNone, ExporterOTLP, ExporterStdout, ExporterFile, name)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	provider = sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+}
+
+func openTraceFile(path string) (io.Writer, error) {
+	if path == "" {
+		path = "traces.json"
+	}
+	var err error
+	traceFile, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	return traceFile, err
+}
+
+// CloseTracing отправляет накопленные спаны
+func CloseTracing() {
+	if provider != nil {
+		err := provider.Shutdown(context.Background())
+		if err != nil {
+			slog.Error("failed to shut down tracer provider", "error", err)
+		}
+	}
+	if traceFile != nil {
+		traceFile.Close()
+	}
+}
+
+// Tracer возвращает трассировщик подсистемы, до InitTracing спаны не записываются
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer("my_app/" + name)
+}
@@ -0,0 +1,269 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"my_app/internal/apikey"
+	"my_app/internal/apperr"
+	"my_app/internal/auth"
+	"my_app/internal/background"
+	"my_app/internal/config"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+// memoryKeyStore - хранилище ключей в памяти вместо таблицы api_keys
+type memoryKeyStore struct {
+	mu      sync.Mutex
+	keys    map[string]*models.APIKey
+	touched map[int32]time.Time
+}
+
+func (s *memoryKeyStore) GetAPIKeyByHash(_ context.Context, hash string) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[hash]
+	if !ok {
+		return nil, apperr.New(apperr.ErrNotFound, "api key not found")
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (s *memoryKeyStore) TouchAPIKey(_ context.Context, id int32, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.touched[id] = usedAt
+	return nil
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	store := &memoryKeyStore{keys: make(map[string]*models.APIKey), touched: make(map[int32]time.Time)}
+	apikey.InitAPIKeys(config.APIKeys{CacheTTL: time.Hour}, store)
+
+	ctx := context.Background()
+	generate := func(id int32, revoked bool, expiresAt *time.Time) string {
+		key, prefix, hash, err := apikey.Generate()
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+		record := &models.APIKey{ID: id, Tenant: "team_a", Name: fmt.Sprint("key_", id), Prefix: prefix, Role: "editor", Hash: hash, ExpiresAt: expiresAt}
+		if revoked {
+			now := time.Now()
+			record.RevokedAt = &now
+		}
+		store.keys[hash] = record
+		return key
+	}
+	expired := time.Now().Add(-time.Minute)
+	valid := generate(1, false, nil)
+	unknown, _, _, _ := apikey.Generate()
+
+	testsuite := []struct {
+		Name string
+		Key  string
+		Err  error
+	}{
+		{Name: "Valid key", Key: valid},
+		{Name: "Unknown key", Key: unknown, Err: apikey.ErrInvalidKey},
+		{Name: "Revoked key", Key: generate(2, true, nil), Err: apikey.ErrRevokedKey},
+		{Name: "Expired key", Key: generate(3, false, &expired), Err: apikey.ErrExpiredKey},
+	}
+	for _, curTest := range testsuite {
+		principal, err := apikey.Authenticate(ctx, curTest.Key)
+		if curTest.Err != nil {
+			if !errors.Is(err, curTest.Err) {
+				t.Fatalf("%s: expected error %v; got %v", curTest.Name, curTest.Err, err)
+			}
+		} else if err != nil || principal.Subject != "api_key:key_1" || principal.Tenant != "team_a" || principal.Roles[0] != "editor" {
+			t.Fatalf("%s: unexpected principal %+v, %v", curTest.Name, principal, err)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+
+	// Время последнего использования обновляется в фоне
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	background.Wait(waitCtx)
+	store.mu.Lock()
+	_, touched := store.touched[1]
+	store.mu.Unlock()
+	if !touched {
+		t.Fatalf("Expected last use of key to be recorded")
+	}
+	t.Log("Last use recorded : Pass")
+
+	// Отозванный ключ перестает работать после сброса кэша, до этого проверка идет из кэша
+	hash := apikey.Hash(valid)
+	now := time.Now()
+	store.keys[hash].RevokedAt = &now
+	if _, err := apikey.Authenticate(ctx, valid); err != nil {
+		t.Fatalf("Expected cached key to be valid; got %v", err)
+	}
+	apikey.Forget(hash)
+	if _, err := apikey.Authenticate(ctx, valid); !errors.Is(err, apikey.ErrRevokedKey) {
+		t.Fatalf("Expected revoked key after cache reset; got %v", err)
+	}
+	t.Log("Revoked key forgotten : Pass")
+}
+
+func TestAPIKeyEscalation(t *testing.T) {
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+
+	// Отказ происходит до обращения к базе данных
+	testsuite := []struct {
+		Name  string
+		Scope string
+		Body  string
+	}{
+		{Name: "Admin role", Scope: "apikey:manage", Body: `{"name": "ci", "role": "admin"}`},
+		{Name: "Role above caller", Scope: "apikey:manage banner:read", Body: `{"name": "ci", "role": "editor"}`},
+		{Name: "Scope above caller", Scope: "apikey:manage banner:read", Body: `{"name": "ci", "role": "user", "scopes": ["banner:delete"]}`},
+		{Name: "Foreign feature", Scope: "apikey:manage banner:read banner:write@1", Body: `{"name": "ci", "role": "user", "scopes": ["banner:write@2"]}`},
+		{Name: "All scopes", Scope: "apikey:manage banner:read banner:write", Body: `{"name": "ci", "role": "user", "scopes": ["*"]}`},
+	}
+	router := server.NewRouter()
+	for _, curTest := range testsuite {
+		claims := jwt.MapClaims{"sub": "tester", "scope": curTest.Scope, "exp": time.Now().Add(time.Hour).Unix()}
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test_secret"))
+		req := httptest.NewRequest(http.MethodPost, "/api_keys", strings.NewReader(curTest.Body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response models.ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != http.StatusForbidden || response.Code != models.ErrorCodeForbidden {
+			t.Fatalf("%s: expected status %v; got %v %s", curTest.Name, http.StatusForbidden, w.Code, w.Body.String())
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+
+	// Инициализация тестовой базы данных
+	cfg := testConfig(t)
+	db.InitDB(cfg.Database)
+	defer db.CloseDB()
+	apikey.InitAPIKeys(cfg.APIKeys, db.APIKeyStore{})
+
+	const tenant = "api_keys"
+	router := server.NewRouter()
+	request := func(credential string, method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+credential)
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	admin := tenantToken(tenant, "admin")
+	// Запрос без feature_id отклоняется обработчиком, то есть после успешной авторизации
+	authenticated := func(key string) bool {
+		w := request(key, http.MethodGet, "/user_banner", "")
+		return w.Code == http.StatusBadRequest
+	}
+
+	name := fmt.Sprintf("service_%d", time.Now().UnixNano())
+	w := request(admin, http.MethodPost, "/api_keys", fmt.Sprintf(`{"name": %q, "role": "user"}`, name))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create api key: %v %s", w.Code, w.Body.String())
+	}
+	var created models.APIKeyCreated
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if !apikey.IsAPIKey(created.Key) || !strings.HasPrefix(created.Key, created.Prefix) || created.Tenant != tenant {
+		t.Fatalf("Unexpected created key %+v", created)
+	}
+	t.Log("Create : Pass")
+
+	w = request(admin, http.MethodGet, "/api_keys", "")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), created.Key) || !strings.Contains(w.Body.String(), name) {
+		t.Fatalf("Expected key in list without secret; got %v %s", w.Code, w.Body.String())
+	}
+	t.Log("List : Pass")
+
+	if !authenticated(created.Key) {
+		t.Fatalf("Expected new key to authenticate")
+	}
+	t.Log("Authenticate : Pass")
+
+	w = request(admin, http.MethodPost, fmt.Sprintf("/api_keys/%d/rotate", created.ID), "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to rotate api key: %v %s", w.Code, w.Body.String())
+	}
+	var rotated models.APIKeyCreated
+	json.Unmarshal(w.Body.Bytes(), &rotated)
+	if rotated.ID != created.ID || rotated.Key == created.Key {
+		t.Fatalf("Expected new secret for key %d; got %+v", created.ID, rotated)
+	}
+	if authenticated(created.Key) || !authenticated(rotated.Key) {
+		t.Fatalf("Expected only rotated secret to authenticate")
+	}
+	t.Log("Rotate : Pass")
+
+	w = request(admin, http.MethodDelete, fmt.Sprintf("/api_keys/%d", created.ID), "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Failed to revoke api key: %v %s", w.Code, w.Body.String())
+	}
+	if w := request(rotated.Key, http.MethodGet, "/user_banner", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected revoked key to be rejected; got %v", w.Code)
+	}
+	if w := request(admin, http.MethodDelete, fmt.Sprintf("/api_keys/%d", created.ID), ""); w.Code != http.StatusNotFound {
+		t.Fatalf("Expected repeated revoke to return %v; got %v", http.StatusNotFound, w.Code)
+	}
+	t.Log("Revoke : Pass")
+
+	// Ключ другого тенанта не виден
+	if w := request(tenantToken("other_tenant", "admin"), http.MethodPost, fmt.Sprintf("/api_keys/%d/rotate", created.ID), ""); w.Code != http.StatusNotFound {
+		t.Fatalf("Expected foreign key to be not found; got %v", w.Code)
+	}
+	t.Log("Foreign tenant : Pass")
+
+	// Изменения ключа попадают в аудит без секрета и хэша
+	w = request(admin, http.MethodGet, fmt.Sprintf("/audit?api_key_id=%d", created.ID), "")
+	var records []models.AuditRecord
+	json.Unmarshal(w.Body.Bytes(), &records)
+	actions := []models.AuditAction{models.AuditAPIKeyRevoke, models.AuditAPIKeyRotate, models.AuditAPIKeyCreate}
+	if w.Code != http.StatusOK || len(records) != len(actions) {
+		t.Fatalf("Expected %d audit records; got %v %s", len(actions), w.Code, w.Body.String())
+	}
+	for i, record := range records {
+		if record.Action != actions[i] || record.APIKeyId == nil || *record.APIKeyId != created.ID || record.BannerId != nil {
+			t.Fatalf("Unexpected audit record %d: %+v", i, record)
+		}
+	}
+	for _, secret := range []string{created.Key, rotated.Key, apikey.Hash(created.Key), apikey.Hash(rotated.Key)} {
+		if strings.Contains(w.Body.String(), secret) {
+			t.Fatalf("Expected audit without key secrets; got %s", w.Body.String())
+		}
+	}
+	t.Log("Audit : Pass")
+
+	// Ключ с правами в пределах прав вызывающего выпускается
+	claims := jwt.MapClaims{"sub": "tester", "tenant": tenant, "scope": "apikey:manage banner:read", "exp": time.Now().Add(time.Hour).Unix()}
+	manager, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test_secret"))
+	if w := request(manager, http.MethodPost, "/api_keys", fmt.Sprintf(`{"name": "%s_user", "role": "user"}`, name)); w.Code != http.StatusCreated {
+		t.Fatalf("Expected key within caller permissions to be created; got %v %s", w.Code, w.Body.String())
+	}
+	t.Log("Key within caller permissions : Pass")
+}
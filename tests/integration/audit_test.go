@@ -0,0 +1,163 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"my_app/internal/auth"
+	"my_app/internal/config"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+func TestAuditLog(t *testing.T) {
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+
+	// Инициализация тестовой базы данных
+	db.InitDB(testConfig(t).Database)
+	defer db.CloseDB()
+
+	const tenant = "audit"
+	suffix := time.Now().UnixNano()
+	editor, admin := fmt.Sprintf("editor_%d", suffix), fmt.Sprintf("admin_%d", suffix)
+	token := func(subject string) string {
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": subject, "role": "admin", "tenant": tenant, "exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("test_secret"))
+		return signed
+	}
+	router := server.NewRouter()
+	request := func(subject string, method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token(subject))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Изменения баннера от двух пользователей
+	start := time.Now().Add(-time.Second)
+	featureId := int(suffix % 1000000)
+	w := request(editor, http.MethodPost, "/banner", fmt.Sprintf(`{"tag_ids": [1], "feature_id": %d, "content": {"title": "a"}, "is_active": true}`, featureId))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create banner: %v %s", w.Code, w.Body.String())
+	}
+	var created models.IdResponse
+	json.Unmarshal(w.Body.Bytes(), &created)
+	bannerPath := fmt.Sprintf("/banner/%d", created.BannerId)
+	steps := []struct {
+		Actor  string
+		Method string
+		Path   string
+		Body   string
+		Code   int
+	}{
+		{Actor: editor, Method: http.MethodPatch, Path: bannerPath, Body: `{"content": {"title": "b"}}`, Code: http.StatusOK},
+		{Actor: editor, Method: http.MethodPost, Path: bannerPath + "/transitions", Body: `{"action": "submit"}`, Code: http.StatusOK},
+		{Actor: admin, Method: http.MethodDelete, Path: bannerPath, Code: http.StatusNoContent},
+	}
+	for _, step := range steps {
+		if w := request(step.Actor, step.Method, step.Path, step.Body); w.Code != step.Code {
+			t.Fatalf("%s %s: expected status %v; got %v %s", step.Method, step.Path, step.Code, w.Code, w.Body.String())
+		}
+	}
+
+	getAudit := func(query url.Values) []models.AuditRecord {
+		query.Set("banner_id", fmt.Sprint(created.BannerId))
+		w := request(admin, http.MethodGet, "/audit?"+query.Encode(), "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %v; got %v %s", query.Encode(), http.StatusOK, w.Code, w.Body.String())
+		}
+		var records []models.AuditRecord
+		if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+			t.Fatalf("Failed to decode audit records: %v", err)
+		}
+		return records
+	}
+	state := func(raw json.RawMessage) map[string]interface{} {
+		var value map[string]interface{}
+		json.Unmarshal(raw, &value)
+		return value
+	}
+
+	// Записи идут от новых к старым, у каждой есть автор и состояние до и после
+	records := getAudit(url.Values{})
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 audit records; got %+v", records)
+	}
+	expected := []struct {
+		Action models.AuditAction
+		Actor  string
+		Before bool
+		After  bool
+	}{
+		{Action: models.AuditDelete, Actor: admin, Before: true},
+		{Action: models.AuditTransition, Actor: editor, Before: true, After: true},
+		{Action: models.AuditUpdate, Actor: editor, Before: true, After: true},
+		{Action: models.AuditCreate, Actor: editor, After: true},
+	}
+	for i, record := range records {
+		if record.Action != expected[i].Action || record.Actor != expected[i].Actor {
+			t.Fatalf("Record %d: expected %s by %s; got %s by %s", i, expected[i].Action, expected[i].Actor, record.Action, record.Actor)
+		}
+		if (state(record.Before) != nil) != expected[i].Before || (state(record.After) != nil) != expected[i].After {
+			t.Fatalf("Record %d: unexpected before/after state %s / %s", i, record.Before, record.After)
+		}
+	}
+	update := records[2]
+	if fmt.Sprint(state(update.Before)["content"]) != "map[title:a]" || fmt.Sprint(state(update.After)["content"]) != "map[title:b]" {
+		t.Fatalf("Expected update from title a to b; got %s / %s", update.Before, update.After)
+	}
+	transition := records[1]
+	if state(transition.Before)["status"] != string(models.RevisionDraft) || state(transition.After)["status"] != string(models.RevisionInReview) {
+		t.Fatalf("Expected transition from draft to review; got %s / %s", transition.Before, transition.After)
+	}
+	t.Log("Banner changes recorded : Pass")
+
+	testsuite := []struct {
+		Name    string
+		Query   url.Values
+		Actions []models.AuditAction
+	}{
+		{Name: "Actor", Query: url.Values{"actor": {admin}}, Actions: []models.AuditAction{models.AuditDelete}},
+		{Name: "Action", Query: url.Values{"action": {"update"}}, Actions: []models.AuditAction{models.AuditUpdate}},
+		{Name: "Time range", Query: url.Values{"from": {start.Format(time.RFC3339)}, "to": {time.Now().Add(time.Minute).Format(time.RFC3339)}}, Actions: []models.AuditAction{models.AuditDelete, models.AuditTransition, models.AuditUpdate, models.AuditCreate}},
+		{Name: "Before range", Query: url.Values{"to": {start.Format(time.RFC3339)}}, Actions: []models.AuditAction{}},
+		{Name: "Page", Query: url.Values{"limit": {"2"}, "offset": {"1"}}, Actions: []models.AuditAction{models.AuditTransition, models.AuditUpdate}},
+		{Name: "Last page", Query: url.Values{"limit": {"2"}, "offset": {"3"}}, Actions: []models.AuditAction{models.AuditCreate}},
+	}
+	for _, curTest := range testsuite {
+		records := getAudit(curTest.Query)
+		if len(records) != len(curTest.Actions) {
+			t.Fatalf("%s: expected actions %v; got %+v", curTest.Name, curTest.Actions, records)
+		}
+		for i, record := range records {
+			if record.Action != curTest.Actions[i] {
+				t.Fatalf("%s: expected actions %v; got %+v", curTest.Name, curTest.Actions, records)
+			}
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+
+	// Ограничения параметров проверяет обработчик
+	for _, query := range []string{"limit=0", "limit=1001"} {
+		if w := request(admin, http.MethodGet, "/audit?"+query, ""); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status %v; got %v", query, http.StatusBadRequest, w.Code)
+		}
+	}
+	t.Log("Invalid limit : Pass")
+}
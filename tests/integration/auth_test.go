@@ -0,0 +1,110 @@
+package server_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"my_app/internal/auth"
+	"my_app/internal/config"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+type authTestsuite struct {
+	Name  string
+	Path  string
+	Token string
+	Code  int
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		}},
+	})
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(jwksPath, jwks, 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write jwks: %v", err)
+	}
+	auth.InitAuth(config.Auth{
+		HS256Secret: "test_secret",
+		JWKSFile:    jwksPath,
+		Audience:    "banner-service",
+	})
+	rbac.InitRBAC(config.RBAC{})
+
+	now := time.Now()
+	claims := func(role interface{}, aud string, exp time.Time, nbf time.Time) jwt.MapClaims {
+		return jwt.MapClaims{"sub": "tester", "role": role, "aud": aud, "exp": exp.Unix(), "nbf": nbf.Unix()}
+	}
+	hs256 := func(c jwt.MapClaims) string {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte("test_secret"))
+		return token
+	}
+	rs256 := func(c jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+		token.Header["kid"] = "test"
+		signed, _ := token.SignedString(rsaKey)
+		return signed
+	}
+	valid := now.Add(time.Hour)
+
+	// Запросы без feature_id отклоняются обработчиком до обращения к базе данных
+	testsuite := []authTestsuite{
+		{Name: "No token", Path: "/user_banner", Code: http.StatusUnauthorized},
+		{Name: "Garbage token", Path: "/user_banner", Token: "user_token", Code: http.StatusUnauthorized},
+		{Name: "HS256 user", Path: "/user_banner", Token: hs256(claims("user", "banner-service", valid, now)), Code: http.StatusBadRequest},
+		{Name: "RS256 roles array", Path: "/user_banner", Token: rs256(claims([]string{"editor", "user"}, "banner-service", valid, now)), Code: http.StatusBadRequest},
+		{Name: "Expired", Path: "/user_banner", Token: hs256(claims("user", "banner-service", now.Add(-time.Minute), now.Add(-time.Hour))), Code: http.StatusUnauthorized},
+		{Name: "Not before", Path: "/user_banner", Token: rs256(claims("user", "banner-service", valid, now.Add(time.Minute))), Code: http.StatusUnauthorized},
+		{Name: "Wrong audience", Path: "/user_banner", Token: hs256(claims("user", "other-service", valid, now)), Code: http.StatusUnauthorized},
+		{Name: "Wrong secret", Path: "/user_banner", Token: func() string {
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims("user", "banner-service", valid, now)).SignedString([]byte("other"))
+			return token
+		}(), Code: http.StatusUnauthorized},
+		{Name: "User on admin route", Path: "/banner", Token: hs256(claims("user", "banner-service", valid, now)), Code: http.StatusForbidden},
+	}
+
+	router := server.NewRouter()
+	for _, curTest := range testsuite {
+		req := httptest.NewRequest(http.MethodGet, curTest.Path, nil)
+		if curTest.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+curTest.Token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		res := w.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != curTest.Code {
+			t.Fatalf("%s: expected status %v; got %v", curTest.Name, curTest.Code, res.StatusCode)
+		}
+		var body map[string]interface{}
+		err := json.NewDecoder(res.Body).Decode(&body)
+		if err != nil || body["error"] == nil {
+			t.Fatalf("%s: expected JSON error body; got %v, %v", curTest.Name, body, err)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
@@ -0,0 +1,113 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"my_app/internal/breaker"
+	"my_app/internal/metrics"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	// Каждый шаг - результат вызова: true - ошибка недоступности, false - успех
+	testsuite := []struct {
+		Name     string
+		Calls    []bool
+		Wait     bool
+		Expected breaker.State
+		Rejected bool
+	}{
+		{Name: "Failures below threshold", Calls: []bool{true, true}, Expected: breaker.Closed},
+		{Name: "Success resets failures", Calls: []bool{true, true, false, true, true}, Expected: breaker.Closed},
+		{Name: "Threshold reached", Calls: []bool{true, true, true}, Expected: breaker.Open, Rejected: true},
+		{Name: "Probe after cooldown", Calls: []bool{true, true, true}, Wait: true, Expected: breaker.HalfOpen},
+		{Name: "Successful probe closes", Calls: []bool{true, true, true, false}, Wait: true, Expected: breaker.Closed},
+		{Name: "Failed probe opens again", Calls: []bool{true, true, true, true}, Wait: true, Expected: breaker.Open, Rejected: true},
+	}
+
+	for _, curTest := range testsuite {
+		b := breaker.New("test", 3, cooldown)
+		for i, failure := range curTest.Calls {
+			// Пробный вызов делается после паузы
+			if curTest.Wait && i == 3 {
+				time.Sleep(cooldown)
+			}
+			if err := b.Allow(); err != nil {
+				t.Fatalf("%s: call %d rejected: %v", curTest.Name, i, err)
+			}
+			b.Record(failure)
+		}
+		if curTest.Wait && len(curTest.Calls) == 3 {
+			time.Sleep(cooldown)
+		}
+		if state := b.State(); state != curTest.Expected {
+			t.Fatalf("%s: expected state %s; got %s", curTest.Name, curTest.Expected, state)
+		}
+		if err := b.Allow(); errors.Is(err, breaker.ErrOpen) != curTest.Rejected {
+			t.Fatalf("%s: expected rejected %v; got %v", curTest.Name, curTest.Rejected, err)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	b := breaker.New("test_probe", 1, time.Millisecond)
+	b.Allow()
+	b.Record(true)
+	if state := testutil.ToFloat64(metrics.BreakerState.WithLabelValues("test_probe")); state != float64(breaker.Open) {
+		t.Fatalf("Expected state metric %d; got %v", breaker.Open, state)
+	}
+	time.Sleep(time.Millisecond)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected probe to be allowed; got %v", err)
+	}
+	// Пока пробный вызов не завершился, остальные отклоняются
+	rejected := testutil.ToFloat64(metrics.BreakerRejections.WithLabelValues("test_probe"))
+	if err := b.Allow(); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("Expected %v during probe; got %v", breaker.ErrOpen, err)
+	}
+	if after := testutil.ToFloat64(metrics.BreakerRejections.WithLabelValues("test_probe")); after != rejected+1 {
+		t.Fatalf("Expected rejection to be counted")
+	}
+	b.Record(false)
+	if state := testutil.ToFloat64(metrics.BreakerState.WithLabelValues("test_probe")); state != float64(breaker.Closed) {
+		t.Fatalf("Expected state metric %d; got %v", breaker.Closed, state)
+	}
+	t.Log("Single probe : Pass")
+}
+
+func TestRetry(t *testing.T) {
+	errTemporary := errors.New("temporary")
+	errPermanent := errors.New("permanent")
+	isFailure := func(err error) bool { return errors.Is(err, errTemporary) }
+	testsuite := []struct {
+		Name     string
+		Errors   []error
+		Retries  int
+		Calls    int
+		Expected error
+	}{
+		{Name: "Success", Errors: []error{nil}, Retries: 2, Calls: 1},
+		{Name: "Success after retry", Errors: []error{errTemporary, nil}, Retries: 2, Calls: 2},
+		{Name: "Retries exhausted", Errors: []error{errTemporary, errTemporary, errTemporary, nil}, Retries: 2, Calls: 3, Expected: errTemporary},
+		{Name: "Permanent error", Errors: []error{errPermanent, nil}, Retries: 2, Calls: 1, Expected: errPermanent},
+		{Name: "No retries", Errors: []error{errTemporary, nil}, Retries: 0, Calls: 1, Expected: errTemporary},
+	}
+
+	for _, curTest := range testsuite {
+		calls := 0
+		err := breaker.Retry(context.Background(), curTest.Retries, time.Millisecond, isFailure, func() error {
+			calls++
+			return curTest.Errors[calls-1]
+		})
+		if !errors.Is(err, curTest.Expected) || calls != curTest.Calls {
+			t.Fatalf("%s: expected %v after %d calls; got %v after %d", curTest.Name, curTest.Expected, curTest.Calls, err, calls)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
@@ -0,0 +1,270 @@
+package server_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"my_app/internal/auth"
+	"my_app/internal/config"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+type bannerImportTestsuite struct {
+	Name     string
+	Format   string
+	Body     string
+	Code     int
+	Total    int
+	ErrorRow []int
+}
+
+func TestBannerImportDryRun(t *testing.T) {
+	testsuite := []bannerImportTestsuite{
+		{
+			Name:   "NDJSON correct",
+			Format: "ndjson",
+			Body: `{"tag_ids":[1,2],"feature_id":0,"content":{"title":"a"},"is_active":true}
+{"banner_id":7,"tag_ids":[3],"feature_id":4,"content":{"title":"b"}}
+`,
+			Code:  http.StatusOK,
+			Total: 2,
+		},
+		{
+			Name:   "NDJSON invalid rows",
+			Format: "ndjson",
+			Body: `{"tag_ids":[],"feature_id":1,"content":{}}
+{"tag_ids":[1],"feature_id":1,"content":{}}
+{"tag_ids":[1],"content":{}}
+not json
+`,
+			Code:     http.StatusBadRequest,
+			Total:    4,
+			ErrorRow: []int{1, 3, 4},
+		},
+		{
+			Name:   "CSV correct",
+			Format: "csv",
+			Body: `tag_ids,feature_id,content,is_active
+"[1,2]",5,"{""title"":""a""}",true
+`,
+			Code:  http.StatusOK,
+			Total: 1,
+		},
+		{
+			Name:   "CSV invalid rows",
+			Format: "csv",
+			Body: `tag_ids,feature_id,content,is_active
+"[1]",x,"{}",true
+"[1]",2,"{}",maybe
+"[1]",2,"{}",false
+`,
+			Code:     http.StatusBadRequest,
+			Total:    3,
+			ErrorRow: []int{2, 3},
+		},
+		{
+			Name:   "Feature outside grant",
+			Format: "ndjson",
+			Body: `{"tag_ids":[1],"feature_id":1,"content":{}}
+{"tag_ids":[1],"feature_id":9,"content":{}}
+`,
+			Code: http.StatusForbidden,
+		},
+	}
+	// Права редактора, ограниченные фичами из тестов
+	permissions := rbac.Permissions{{Scope: rbac.ScopeBannerWrite, Features: map[int32]bool{0: true, 1: true, 2: true, 4: true, 5: true}}}
+
+	// Инициализация тестовой базы данных, из нее читаются схемы фич
+	db.InitDB(testConfig(t).Database)
+	t.Log("Сonnected to db")
+	defer db.CloseDB()
+
+	for _, curTest := range testsuite {
+		req := httptest.NewRequest(http.MethodPost, "/banner/import?dry_run=true&format="+curTest.Format, strings.NewReader(curTest.Body))
+		req = req.WithContext(context.WithValue(req.Context(), server.PermissionsKey, permissions))
+		w := httptest.NewRecorder()
+		server.Wrapper.BannerImport(w, req)
+		res := w.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != curTest.Code {
+			t.Fatalf("%s: expected status %v; got %v", curTest.Name, curTest.Code, res.StatusCode)
+		}
+		if curTest.Code == http.StatusForbidden {
+			var response models.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&response)
+			if err != nil || response.Code != models.ErrorCodeForbidden {
+				t.Fatalf("%s: expected code %q; got %+v (%v)", curTest.Name, models.ErrorCodeForbidden, response, err)
+			}
+			t.Log(curTest.Name, ": Pass")
+			continue
+		}
+		if curTest.Code == http.StatusBadRequest {
+			// Ошибки строк приходят в общем формате ошибок, field - номер строки
+			var response models.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&response)
+			if err != nil || response.Code != models.ErrorCodeValidation || !strings.Contains(response.Error, fmt.Sprintf("of %d rows", curTest.Total)) {
+				t.Fatalf("%s: expected code %q for %d rows; got %+v (%v)", curTest.Name, models.ErrorCodeValidation, curTest.Total, response, err)
+			}
+			if len(response.Fields) != len(curTest.ErrorRow) {
+				t.Fatalf("%s: expected errors in rows %v; got %+v", curTest.Name, curTest.ErrorRow, response.Fields)
+			}
+			for i, field := range response.Fields {
+				if field.Field != fmt.Sprintf("row %d", curTest.ErrorRow[i]) || field.Error == "" {
+					t.Fatalf("%s: expected errors in rows %v; got %+v", curTest.Name, curTest.ErrorRow, response.Fields)
+				}
+			}
+			t.Log(curTest.Name, ": Pass")
+			continue
+		}
+		var result models.ImportResult
+		err := json.NewDecoder(res.Body).Decode(&result)
+		if err != nil {
+			t.Fatalf("%s: failed to decode response body: %v", curTest.Name, err)
+		}
+		if !result.DryRun || result.Imported != 0 {
+			t.Fatalf("%s: dry run must not import banners, got %+v", curTest.Name, result)
+		}
+		if result.Total != curTest.Total {
+			t.Fatalf("%s: expected total %v; got %v", curTest.Name, curTest.Total, result.Total)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
+
+func TestBannerImportExport(t *testing.T) {
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+
+	// Инициализация тестовой базы данных
+	ctx := context.Background()
+	db.InitDB(testConfig(t).Database)
+	defer db.CloseDB()
+
+	const tenant = "bulk"
+	featureId := int(time.Now().UnixNano() % 1000000)
+	router := server.NewRouter()
+	request := func(method string, path string, contentType string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+tenantToken(tenant, "admin"))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	var bannerIds []int32
+	defer func() {
+		for _, bannerId := range bannerIds {
+			db.DeleteBanner(ctx, tenant, int(bannerId), "test")
+		}
+	}()
+
+	// Импорт в обоих форматах записывает баннеры
+	imports := []struct {
+		Name        string
+		ContentType string
+		Body        string
+		Imported    int
+	}{
+		{
+			Name:        "NDJSON import",
+			ContentType: "application/x-ndjson",
+			Body: fmt.Sprintf(`{"tag_ids":[1,2],"feature_id":%d,"content":{"title":"a"},"is_active":true}
+{"tag_ids":[3],"feature_id":%d,"content":{"title":"b"}}
+`, featureId, featureId),
+			Imported: 2,
+		},
+		{
+			Name:        "CSV import",
+			ContentType: "text/csv",
+			Body: fmt.Sprintf(`tag_ids,feature_id,content,is_active
+"[4]",%d,"{""title"":""c""}",true
+`, featureId),
+			Imported: 1,
+		},
+	}
+	for _, curTest := range imports {
+		w := request(http.MethodPost, "/banner/import", curTest.ContentType, curTest.Body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("%s: expected status %v; got %v %s", curTest.Name, http.StatusCreated, w.Code, w.Body.String())
+		}
+		var result models.ImportResult
+		json.Unmarshal(w.Body.Bytes(), &result)
+		bannerIds = append(bannerIds, result.BannerIds...)
+		if result.DryRun || result.Imported != curTest.Imported || len(result.BannerIds) != curTest.Imported {
+			t.Fatalf("%s: expected %d imported banners; got %+v", curTest.Name, curTest.Imported, result)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+
+	// Выгрузка в обоих форматах возвращает импортированные баннеры по порядку id
+	exportPath := fmt.Sprintf("/banner/export?feature_id=%d&format=", featureId)
+	titles := []string{"a", "b", "c"}
+	w := request(http.MethodGet, exportPath+"ndjson", "", "")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/x-ndjson") {
+		t.Fatalf("NDJSON export: unexpected response %v %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != len(titles) {
+		t.Fatalf("NDJSON export: expected %d rows; got %s", len(titles), w.Body.String())
+	}
+	for i, line := range lines {
+		var row struct {
+			ID        int32           `json:"banner_id"`
+			FeatureId int             `json:"feature_id"`
+			Content   models.ModelMap `json:"content"`
+		}
+		err := json.Unmarshal([]byte(line), &row)
+		if err != nil || row.ID != bannerIds[i] || row.FeatureId != featureId || row.Content["title"] != titles[i] {
+			t.Fatalf("NDJSON export: unexpected row %d %s (%v)", i, line, err)
+		}
+	}
+	t.Log("NDJSON export : Pass")
+
+	w = request(http.MethodGet, exportPath+"csv", "", "")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("CSV export: unexpected response %v %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	if err != nil || len(records) != len(titles)+1 {
+		t.Fatalf("CSV export: expected header and %d rows; got %s (%v)", len(titles), w.Body.String(), err)
+	}
+	if strings.Join(records[0], ",") != "banner_id,tag_ids,feature_id,content,is_active,created_at,updated_at" {
+		t.Fatalf("CSV export: unexpected header %v", records[0])
+	}
+	for i, record := range records[1:] {
+		if record[0] != fmt.Sprint(bannerIds[i]) || record[2] != fmt.Sprint(featureId) || record[3] != fmt.Sprintf(`{"title":%q}`, titles[i]) {
+			t.Fatalf("CSV export: unexpected row %d %v", i, record)
+		}
+	}
+	t.Log("CSV export : Pass")
+
+	// Ошибка во втором баннере откатывает и первый: content нельзя записать в JSON.
+	// В ошибке номер строки входных данных, а не позиция в списке
+	rollbackFeature := featureId + 1
+	_, err = db.ImportBanners(ctx, tenant, []models.ImportedBanner{
+		{Row: 3, Banner: models.BannerNoId{TagIds: []int32{1}, FeatureId: int32(rollbackFeature), Content: models.ModelMap{"title": "a"}}},
+		{Row: 5, Banner: models.BannerNoId{TagIds: []int32{1}, FeatureId: int32(rollbackFeature), Content: models.ModelMap{"score": math.Inf(1)}}},
+	}, "tester")
+	if err == nil || !strings.Contains(err.Error(), "row 5") {
+		t.Fatalf("Expected import error in row 5; got %v", err)
+	}
+	banners, err := db.GetBanners(ctx, tenant, &rollbackFeature, nil, nil, nil)
+	if err != nil || len(banners) != 0 {
+		t.Fatalf("Expected import to be rolled back; got %+v (%v)", banners, err)
+	}
+	t.Log("Rollback : Pass")
+}
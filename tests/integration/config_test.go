@@ -0,0 +1,171 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"my_app/internal/config"
+	"my_app/internal/server"
+)
+
+// testConfig собирает настройки из окружения тестового контейнера
+func testConfig(t *testing.T) *config.Config {
+	cfg, err := config.Parse(nil)
+	if err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	return cfg
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+server:
+  port: 9000
+database:
+  host: file-host
+  user: file-user
+  max_open_conns: 50
+cache:
+  host: file-cache
+  ttl: 10m
+locale:
+  fallback: [en, ru]
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Окружение тестового контейнера не должно влиять на проверку
+	for _, name := range []string{"DB_USER", "DB_PORT", "APP_PORT", "LOCALE_FALLBACK", "FEATURE_PREVIEW"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("CACHE_TTL", "20m")
+
+	cfg, err := config.Parse([]string{"-config", path, "-cache.ttl", "30m", "-server.port=9100"})
+	if err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	testsuite := []struct {
+		Name     string
+		Got      interface{}
+		Expected interface{}
+	}{
+		{Name: "Default", Got: cfg.Database.Port, Expected: 5432},
+		{Name: "File", Got: cfg.Database.User, Expected: "file-user"},
+		{Name: "File list", Got: strings.Join(cfg.Locale.Fallback, ","), Expected: "en,ru"},
+		{Name: "Env over file", Got: cfg.Database.Host, Expected: "env-host"},
+		{Name: "Flag over env", Got: cfg.Cache.TTL, Expected: 30 * time.Minute},
+		{Name: "Flag over file", Got: cfg.Server.Port, Expected: 9100},
+		{Name: "Default feature toggle", Got: cfg.Features.Preview, Expected: true},
+	}
+	for _, curTest := range testsuite {
+		if curTest.Got != curTest.Expected {
+			t.Fatalf("%s: expected %v; got %v", curTest.Name, curTest.Expected, curTest.Got)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
+
+func TestConfigUnknownKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte("database:\n  hots: typo\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = config.Parse([]string{"-config", path}); err == nil {
+		t.Fatalf("Expected error for unknown key")
+	}
+	t.Log("Unknown key : Pass")
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := func() *config.Config {
+		cfg, err := config.Parse([]string{
+			"-database.host", "db", "-database.user", "app", "-database.name", "banners",
+			"-cache.host", "redis", "-auth.hs256_secret", "secret", "-preview.secret", "secret",
+		})
+		if err != nil {
+			t.Fatalf("Failed to parse config: %v", err)
+		}
+		return cfg
+	}
+	testsuite := []struct {
+		Name   string
+		Modify func(cfg *config.Config)
+		Error  string
+	}{
+		{Name: "Valid", Modify: func(cfg *config.Config) {}},
+		{Name: "Port", Modify: func(cfg *config.Config) { cfg.Server.Port = 70000 }, Error: "server.port"},
+		{Name: "TLS without key", Modify: func(cfg *config.Config) { cfg.Server.TLSCertFile = "cert.pem" }, Error: "tls_key_file"},
+		{Name: "Pool sizes", Modify: func(cfg *config.Config) { cfg.Database.MaxOpenConns = 5 }, Error: "max_idle_conns"},
+		{Name: "No auth keys", Modify: func(cfg *config.Config) { cfg.Auth.HS256Secret = "" }, Error: "auth.hs256_secret"},
+		{Name: "No preview secret", Modify: func(cfg *config.Config) { cfg.Preview.Secret = "" }, Error: "preview.secret"},
+		{Name: "Preview disabled", Modify: func(cfg *config.Config) { cfg.Preview.Secret, cfg.Features.Preview = "", false }},
+		{Name: "Exporter", Modify: func(cfg *config.Config) { cfg.Tracing.Exporter = "jaeger" }, Error: "tracing.exporter"},
+		{Name: "Log level", Modify: func(cfg *config.Config) { cfg.Logging.Level = "verbose" }, Error: "logging.level"},
+	}
+	for _, curTest := range testsuite {
+		cfg := valid()
+		curTest.Modify(cfg)
+		err := cfg.Validate()
+		if curTest.Error == "" && err != nil {
+			t.Fatalf("%s: unexpected error %v", curTest.Name, err)
+		}
+		if curTest.Error != "" && (err == nil || !strings.Contains(err.Error(), curTest.Error)) {
+			t.Fatalf("%s: expected error about %s; got %v", curTest.Name, curTest.Error, err)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
+
+func TestConfigPrintRedactsSecrets(t *testing.T) {
+	cfg, err := config.Parse([]string{"-database.password", "db-secret", "-auth.hs256_secret", "jwt-secret"})
+	if err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	var out bytes.Buffer
+	if err = config.Print(&out, cfg); err != nil {
+		t.Fatalf("Failed to print config: %v", err)
+	}
+	if strings.Contains(out.String(), "db-secret") || strings.Contains(out.String(), "jwt-secret") {
+		t.Fatalf("Expected secrets to be redacted:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "password: <redacted>") || cfg.Database.Password != "db-secret" {
+		t.Fatalf("Expected redacted password in output and original value in config:\n%s", out.String())
+	}
+	t.Log("Redacted secrets : Pass")
+}
+
+func TestFeatureToggles(t *testing.T) {
+	cfg := testConfig(t)
+	defer server.InitServer(cfg.Server, cfg.Features)
+	server.InitServer(cfg.Server, config.Features{Preview: true, Bulk: false, APIKeys: true, Metrics: false})
+	router := server.NewRouter()
+
+	testsuite := []struct {
+		Name   string
+		Method string
+		Path   string
+		Code   int
+	}{
+		{Name: "Disabled metrics", Method: http.MethodGet, Path: "/metrics", Code: http.StatusNotFound},
+		{Name: "Disabled export", Method: http.MethodGet, Path: "/banner/export", Code: http.StatusNotFound},
+		{Name: "Enabled API keys", Method: http.MethodGet, Path: "/api_keys", Code: http.StatusUnauthorized},
+	}
+	for _, curTest := range testsuite {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(curTest.Method, curTest.Path, nil))
+		if w.Code != curTest.Code {
+			t.Fatalf("%s: expected status code %d; got %d", curTest.Name, curTest.Code, w.Code)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
@@ -0,0 +1,110 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"my_app/internal/apperr"
+	"my_app/internal/auth"
+	"my_app/internal/breaker"
+	"my_app/internal/config"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+func TestErrorStatus(t *testing.T) {
+	testsuite := []struct {
+		Name     string
+		Err      error
+		Expected int
+	}{
+		{Name: "Banner not found", Err: db.ErrBannerNotFound, Expected: http.StatusNotFound},
+		{Name: "No locale", Err: db.ErrNoLocale, Expected: http.StatusNotFound},
+		{Name: "API key not found", Err: db.ErrAPIKeyNotFound, Expected: http.StatusNotFound},
+		{Name: "Revision locked", Err: db.ErrRevisionLocked, Expected: http.StatusConflict},
+		{Name: "Invalid transition", Err: db.ErrInvalidTransition, Expected: http.StatusConflict},
+		{Name: "Unknown transition", Err: db.ErrUnknownTransition, Expected: http.StatusBadRequest},
+		{Name: "Breaker open", Err: breaker.ErrOpen, Expected: http.StatusServiceUnavailable},
+		{Name: "Query timeout", Err: context.DeadlineExceeded, Expected: http.StatusServiceUnavailable},
+		{Name: "Wrapped", Err: fmt.Errorf("delete banner: %w", db.ErrBannerNotFound), Expected: http.StatusNotFound},
+		{Name: "Wrap keeps cause", Err: apperr.Wrap(apperr.ErrValidation, errors.New("bad")), Expected: http.StatusBadRequest},
+		{Name: "Untyped", Err: errors.New("unexpected"), Expected: http.StatusInternalServerError},
+	}
+
+	for _, curTest := range testsuite {
+		if status := apperr.HTTPStatus(curTest.Err); status != curTest.Expected {
+			t.Fatalf("%s: expected status %d; got %d", curTest.Name, curTest.Expected, status)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+	// Конкретная ошибка по-прежнему отличима от других ошибок того же вида
+	if errors.Is(db.ErrBannerNotFound, db.ErrAPIKeyNotFound) || !errors.Is(db.ErrBannerNotFound, apperr.ErrNotFound) {
+		t.Fatalf("Expected sentinel errors to keep their identity")
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+	token := func(role string) string {
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "tester", "role": role, "exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("test_secret"))
+		return signed
+	}
+	// Запросы отклоняются до обращения к базе данных
+	testsuite := []struct {
+		Name      string
+		Method    string
+		Path      string
+		Token     string
+		Status    int
+		Code      string
+		RequestId bool
+	}{
+		{Name: "No token", Method: http.MethodGet, Path: "/user_banner", Status: http.StatusUnauthorized, Code: models.ErrorCodeUnauthorized, RequestId: true},
+		{Name: "No scope", Method: http.MethodGet, Path: "/banner?feature_id=1", Token: token("user"), Status: http.StatusForbidden, Code: models.ErrorCodeForbidden, RequestId: true},
+		{Name: "Invalid limit", Method: http.MethodGet, Path: "/banner?feature_id=1&limit=x", Token: token("admin"), Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, RequestId: true},
+		{Name: "Unknown route", Method: http.MethodGet, Path: "/unknown", Status: http.StatusNotFound, Code: models.ErrorCodeNotFound},
+		{Name: "Method not allowed", Method: http.MethodPut, Path: "/user_banner", Status: http.StatusMethodNotAllowed, Code: models.ErrorCodeMethodNotAllowed},
+	}
+
+	router := server.NewRouter()
+	for _, curTest := range testsuite {
+		req := httptest.NewRequest(curTest.Method, curTest.Path, nil)
+		if curTest.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+curTest.Token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != curTest.Status {
+			t.Fatalf("%s: expected status %d; got %d", curTest.Name, curTest.Status, w.Code)
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != "application/json; charset=UTF-8" {
+			t.Fatalf("%s: expected JSON error; got Content-Type %q", curTest.Name, contentType)
+		}
+		var response models.ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: failed to decode error: %v", curTest.Name, err)
+		}
+		if response.Code != curTest.Code || response.Error == "" {
+			t.Fatalf("%s: expected code %q with message; got %+v", curTest.Name, curTest.Code, response)
+		}
+		// Идентификатор в теле совпадает с заголовком, по нему ошибку можно найти в логах
+		if curTest.RequestId && (response.RequestId == "" || response.RequestId != w.Header().Get(server.RequestIDHeader)) {
+			t.Fatalf("%s: expected request id %q; got %q", curTest.Name, w.Header().Get(server.RequestIDHeader), response.RequestId)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
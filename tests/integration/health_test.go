@@ -0,0 +1,116 @@
+package server_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/server"
+)
+
+func TestHealthz(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	server.NewRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d; got %d", http.StatusOK, w.Code)
+	}
+	var response models.HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil || response.Status != models.HealthOK {
+		t.Fatalf("Expected status %q; got %+v (%v)", models.HealthOK, response, err)
+	}
+	t.Log("Liveness : Pass")
+}
+
+func TestReadyz(t *testing.T) {
+	// Зависимости в заданном состоянии: закрытый пул базы данных,
+	// Redis на закрытом порту и миграции, которые не применены
+	ctx := context.Background()
+	closedDB, err := sql.Open("postgres", "postgres://127.0.0.1:1/banners?sslmode=disable")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	closedDB.Close()
+	unreachableCache := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	defer unreachableCache.Close()
+	ok := func(context.Context) error { return nil }
+	closed := func(ctx context.Context) error { return closedDB.PingContext(ctx) }
+	unreachable := func(ctx context.Context) error { return unreachableCache.Ping(ctx).Err() }
+	notMigrated := func(context.Context) error { return db.ErrNotMigrated }
+	defer server.InitHealth(server.DefaultHealthDependencies)
+
+	testsuite := []struct {
+		Name         string
+		Dependencies server.HealthDependencies
+		Code         int
+		Status       string
+		Checks       map[string]string
+	}{
+		{
+			Name:         "Ready",
+			Dependencies: server.HealthDependencies{Database: ok, Migrations: ok, Cache: ok},
+			Code:         http.StatusOK, Status: models.HealthOK,
+			Checks: map[string]string{"database": models.HealthOK, "migrations": models.HealthOK, "cache": models.HealthOK},
+		},
+		{
+			Name:         "Unreachable cache",
+			Dependencies: server.HealthDependencies{Database: ok, Migrations: ok, Cache: unreachable},
+			Code:         http.StatusOK, Status: models.HealthDegraded,
+			Checks: map[string]string{"database": models.HealthOK, "migrations": models.HealthOK, "cache": models.HealthDegraded},
+		},
+		{
+			Name:         "Closed database",
+			Dependencies: server.HealthDependencies{Database: closed, Migrations: ok, Cache: ok},
+			Code:         http.StatusServiceUnavailable, Status: models.HealthFail,
+			Checks: map[string]string{"database": models.HealthFail, "migrations": models.HealthOK, "cache": models.HealthOK},
+		},
+		{
+			Name:         "Missing migrations",
+			Dependencies: server.HealthDependencies{Database: ok, Migrations: notMigrated, Cache: ok},
+			Code:         http.StatusServiceUnavailable, Status: models.HealthFail,
+			Checks: map[string]string{"database": models.HealthOK, "migrations": models.HealthFail, "cache": models.HealthOK},
+		},
+		{
+			Name:         "Everything down",
+			Dependencies: server.HealthDependencies{Database: closed, Migrations: notMigrated, Cache: unreachable},
+			Code:         http.StatusServiceUnavailable, Status: models.HealthFail,
+			Checks: map[string]string{"database": models.HealthFail, "migrations": models.HealthFail, "cache": models.HealthDegraded},
+		},
+	}
+
+	router := server.NewRouter()
+	for _, curTest := range testsuite {
+		server.InitHealth(curTest.Dependencies)
+		req := httptest.NewRequest(http.MethodGet, "/readyz", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response models.HealthResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", curTest.Name, err)
+		}
+		if w.Code != curTest.Code || response.Status != curTest.Status {
+			t.Fatalf("%s: expected %d %q; got %d %q", curTest.Name, curTest.Code, curTest.Status, w.Code, response.Status)
+		}
+		for name, status := range curTest.Checks {
+			check, found := response.Checks[name]
+			if !found || check.Status != status {
+				t.Fatalf("%s: expected %s status %q; got %+v", curTest.Name, name, status, check)
+			}
+			if (status != models.HealthOK) != (check.Error != "") {
+				t.Fatalf("%s: unexpected %s error %q", curTest.Name, name, check.Error)
+			}
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
@@ -0,0 +1,103 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"my_app/internal/auth"
+	"my_app/internal/config"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+func TestBannerPostIdempotency(t *testing.T) {
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+
+	// Инициализация тестовой базы данных, ключи быстро истекают
+	ctx := context.Background()
+	cfg := testConfig(t)
+	cfg.Database.IdempotencyTTL = 300 * time.Millisecond
+	db.InitDB(cfg.Database)
+	defer db.CloseDB()
+
+	const tenant = "idempotency"
+	featureId := int(time.Now().UnixNano() % 1000000)
+	body := fmt.Sprintf(`{"tag_ids": [1, 2], "feature_id": %d, "content": {"title": "a", "text": "b"}, "is_active": true}`, featureId)
+	// Тот же запрос с другими пробелами и порядком ключей
+	reordered := fmt.Sprintf(`{"is_active":true,"content":{"text":"b","title":"a"},"feature_id":%d,"tag_ids":[1,2]}`, featureId)
+	changed := fmt.Sprintf(`{"tag_ids": [1, 2], "feature_id": %d, "content": {"title": "c"}, "is_active": true}`, featureId)
+	key := fmt.Sprintf("key-%d", time.Now().UnixNano())
+
+	var bannerIds []int32
+	defer func() {
+		for _, bannerId := range bannerIds {
+			db.DeleteBanner(ctx, tenant, int(bannerId), "test")
+		}
+	}()
+	router := server.NewRouter()
+	post := func(key string, body string) (*httptest.ResponseRecorder, models.IdResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/banner", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+tenantToken(tenant, "admin"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response models.IdResponse
+		if w.Code == http.StatusCreated {
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response body: %v", err)
+			}
+			bannerIds = append(bannerIds, response.BannerId)
+		}
+		return w, response
+	}
+
+	w, first := post(key, body)
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("Expected new banner; got %v %s", w.Code, w.Body.String())
+	}
+	t.Log("First request : Pass")
+
+	w, replay := post(key, reordered)
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" || replay.BannerId != first.BannerId {
+		t.Fatalf("Expected replay of banner %d; got %v %s", first.BannerId, w.Code, w.Body.String())
+	}
+	t.Log("Replay with reordered body : Pass")
+
+	w, _ = post(key, changed)
+	var errorResponse models.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	if w.Code != http.StatusUnprocessableEntity || errorResponse.Code != models.ErrorCodeIdempotencyKey {
+		t.Fatalf("Expected status %v with code %q; got %v %s", http.StatusUnprocessableEntity, models.ErrorCodeIdempotencyKey, w.Code, w.Body.String())
+	}
+	t.Log("Different body : Pass")
+
+	// После истечения ключ можно использовать снова
+	time.Sleep(500 * time.Millisecond)
+	w, renewed := post(key, changed)
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" || renewed.BannerId == first.BannerId {
+		t.Fatalf("Expected new banner for expired key; got %v %s", w.Code, w.Body.String())
+	}
+	t.Log("Expired key : Pass")
+
+	// Просроченные ключи удаляются, даже если к ним больше не обращаются
+	w, _ = post(key+"-abandoned", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected new banner; got %v %s", w.Code, w.Body.String())
+	}
+	time.Sleep(500 * time.Millisecond)
+	deleted, err := db.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil || deleted == 0 {
+		t.Fatalf("Expected expired keys to be deleted; got %v, %v", deleted, err)
+	}
+	t.Log("Expired key cleanup : Pass")
+}
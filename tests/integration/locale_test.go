@@ -0,0 +1,96 @@
+package server_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"my_app/internal/cache"
+	"my_app/internal/config"
+	"my_app/internal/locale"
+	"my_app/internal/models"
+)
+
+type localizeTestsuite struct {
+	Name      string
+	Requested []string
+	Locale    string
+	Found     bool
+}
+
+func TestLocalize(t *testing.T) {
+	locale.InitLocale(config.Locale{Fallback: []string{"en"}})
+
+	requested := locale.ParseAcceptLanguage("ru;q=0.5, de-AT, fr;q=0, en-US;q=0.8")
+	if !reflect.DeepEqual(requested, []string{"de-at", "en-us", "ru"}) {
+		t.Fatalf("Unexpected Accept-Language order %v", requested)
+	}
+	candidates := locale.Candidates(requested)
+	if !reflect.DeepEqual(candidates, []string{"de-at", "de", "en-us", "en", "ru"}) {
+		t.Fatalf("Unexpected candidates %v", candidates)
+	}
+
+	banner := models.BannerExpanded{
+		Content: models.ModelMap{
+			locale.ContentKey: map[string]interface{}{
+				"en":    map[string]interface{}{"title": "Hello"},
+				"pt-BR": map[string]interface{}{"title": "Olá"},
+			},
+		},
+	}
+	testsuite := []localizeTestsuite{
+		{Name: "Exact match", Requested: []string{"pt_BR"}, Locale: "pt-BR", Found: true},
+		{Name: "Fallback chain", Requested: []string{"ru"}, Locale: "en", Found: true},
+		{Name: "Base language", Requested: []string{"en-GB"}, Locale: "en", Found: true},
+	}
+	for _, curTest := range testsuite {
+		localized, ok := locale.Localize(&banner, locale.Candidates(curTest.Requested))
+		if ok != curTest.Found {
+			t.Fatalf("%s: expected found %v; got %v", curTest.Name, curTest.Found, ok)
+		}
+		if localized.Locale != curTest.Locale {
+			t.Fatalf("%s: expected locale %q; got %q", curTest.Name, curTest.Locale, localized.Locale)
+		}
+		if !reflect.DeepEqual(localized.Locales, []string{"en", "pt-BR"}) {
+			t.Fatalf("%s: unexpected locales %v", curTest.Name, localized.Locales)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+
+	locale.InitLocale(config.Locale{})
+	if _, ok := locale.Localize(&banner, locale.Candidates([]string{"ru"})); ok {
+		t.Fatalf("Expected no locale without fallback chain")
+	}
+	plain := models.BannerExpanded{Content: models.ModelMap{"title": "Hello"}}
+	localized, ok := locale.Localize(&plain, locale.Candidates([]string{"ru"}))
+	if !ok || localized.Locale != "" || !reflect.DeepEqual(localized.Content, plain.Content) {
+		t.Fatalf("Expected plain content to be returned as is, got %+v", localized)
+	}
+}
+
+func TestUserBannerCacheLocale(t *testing.T) {
+	locale.InitLocale(config.Locale{})
+
+	// Инициализация тестового кэша
+	ctx := context.Background()
+	cache.InitCache(testConfig(t).Cache)
+	defer cache.CloseCache()
+
+	// Локаль баннера в каноничной форме BCP 47, запрос - в нормализованной
+	featureId := int(time.Now().UnixNano() % 1000000)
+	tagId := 1
+	banner := &models.LocalizedBanner{
+		BannerExpanded: models.BannerExpanded{ID: 1, FeatureId: int32(featureId), Content: models.ModelMap{"title": "Olá"}, IsActive: true},
+		Locale:         "pt-BR",
+		Locales:        []string{"en", "pt-BR"},
+	}
+	if err := cache.SaveBannerToCache(ctx, "locale_cache", &featureId, &tagId, banner); err != nil {
+		t.Fatalf("Failed to save banner to cache: %v", err)
+	}
+	cached, err := cache.GetBannerFromCache(ctx, "locale_cache", &featureId, &tagId, locale.ParseAcceptLanguage("pt-BR"))
+	if err != nil || cached.Locale != "pt-BR" || cached.Content["title"] != "Olá" {
+		t.Fatalf("Expected cached pt-BR banner; got %+v, %v", cached, err)
+	}
+	t.Log("Mixed case locale : Pass")
+}
@@ -0,0 +1,142 @@
+package server_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"my_app/internal/config"
+	"my_app/internal/logging"
+	"my_app/internal/server"
+	"my_app/internal/templating"
+)
+
+func TestRequestID(t *testing.T) {
+	// Лог пишется в stdout, на время теста он перехватывается
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = writer
+	logging.InitLogging(config.Logging{Level: "info"})
+	defer func() {
+		os.Stdout = stdout
+		logging.InitLogging(config.Logging{Level: "info"})
+	}()
+
+	tests := []struct {
+		Name     string
+		Header   string
+		Expected string
+	}{
+		{Name: "Client request id", Header: "req-42", Expected: "req-42"},
+		{Name: "No request id"},
+		{Name: "Invalid request id", Header: "bad id\n" + strings.Repeat("x", 10)},
+		{Name: "Too long request id", Header: strings.Repeat("x", 129)},
+	}
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	router := server.NewRouter()
+	var ids []string
+	for _, curTest := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/user_banner", nil)
+		if curTest.Header != "" {
+			req.Header.Set(server.RequestIDHeader, curTest.Header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		id := w.Header().Get(server.RequestIDHeader)
+		if curTest.Expected != "" && id != curTest.Expected {
+			t.Fatalf("%s: expected request id %q; got %q", curTest.Name, curTest.Expected, id)
+		}
+		if curTest.Expected == "" && !generated.MatchString(id) {
+			t.Fatalf("%s: expected generated request id; got %q", curTest.Name, id)
+		}
+		ids = append(ids, id)
+		t.Log(curTest.Name, ": Pass")
+	}
+	writer.Close()
+
+	logged := make(map[string]bool)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var line struct {
+			Msg       string `json:"msg"`
+			RequestID string `json:"request_id"`
+			Status    int    `json:"status"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Log line is not JSON: %s", scanner.Text())
+		}
+		if line.Msg == "request" && line.Status == http.StatusUnauthorized {
+			logged[line.RequestID] = true
+		}
+	}
+	for _, id := range ids {
+		if !logged[id] {
+			t.Fatalf("Expected log line with request id %q", id)
+		}
+	}
+	t.Log("Request log lines : Pass")
+}
+
+func TestRequestLogRedaction(t *testing.T) {
+	templating.InitTemplating(config.Templating{Vars: []string{"name", "city"}})
+
+	// Лог пишется в stdout, на время теста он перехватывается
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = writer
+	logging.InitLogging(config.Logging{Level: "info"})
+	defer func() {
+		os.Stdout = stdout
+		logging.InitLogging(config.Logging{Level: "info"})
+	}()
+
+	testsuite := []struct {
+		Name string
+		URI  string
+		Want string
+	}{
+		{Name: "Preview token", URI: "/user_banner?preview_token=secret.sig", Want: "/user_banner?preview_token=redacted"},
+		{Name: "Template vars", URI: "/user_banner?feature_id=1&tag_id=2&name=Anna&city=Kazan", Want: "/user_banner?city=redacted&feature_id=1&name=redacted&tag_id=2"},
+		{Name: "No query", URI: "/user_banner", Want: "/user_banner"},
+	}
+	router := server.NewRouter()
+	for _, curTest := range testsuite {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, curTest.URI, nil))
+	}
+	writer.Close()
+	os.Stdout = stdout
+
+	var uris []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var line struct {
+			Msg string `json:"msg"`
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err == nil && line.Msg == "request" {
+			uris = append(uris, line.URI)
+		}
+	}
+	if len(uris) != len(testsuite) {
+		t.Fatalf("Expected %d request log lines; got %v", len(testsuite), uris)
+	}
+	for i, curTest := range testsuite {
+		if uris[i] != curTest.Want {
+			t.Fatalf("%s: expected uri %q; got %q", curTest.Name, curTest.Want, uris[i])
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
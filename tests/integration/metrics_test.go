@@ -0,0 +1,45 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"my_app/internal/server"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	router := server.NewRouter()
+
+	// Запрос без токена тоже попадает в метрики
+	req := httptest.NewRequest(http.MethodGet, "/user_banner", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status %v; got %v", http.StatusUnauthorized, w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %v; got %v", http.StatusOK, res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+
+	for _, metric := range []string{
+		`banner_service_http_requests_total{method="GET",route="UserBannerGet",status="401"}`,
+		`banner_service_http_request_duration_seconds_bucket{method="GET",route="UserBannerGet",status="401",le="+Inf"}`,
+		`banner_service_cache_async_writes_in_flight`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(string(body), metric) {
+			t.Fatalf("Expected metric %s in response", metric)
+		}
+		t.Log(metric, ": Pass")
+	}
+}
@@ -0,0 +1,56 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"my_app/internal/config"
+	"my_app/internal/preview"
+	"my_app/internal/server"
+)
+
+func TestPreviewToken(t *testing.T) {
+	preview.InitPreview(config.Preview{Secret: "test_secret"})
+
+	token, expiresAt, err := preview.Sign("team_a", 42, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to sign preview token: %v", err)
+	}
+	if time.Until(expiresAt) > time.Minute {
+		t.Fatalf("Unexpected expiration %v", expiresAt)
+	}
+	tenant, bannerId, err := preview.Verify(token)
+	if err != nil || tenant != "team_a" || bannerId != 42 {
+		t.Fatalf("Expected banner 42 of team_a; got %v, %v, %v", tenant, bannerId, err)
+	}
+
+	// Подмена идентификатора баннера или тенанта ломает подпись
+	parts := strings.Split(token, ".")
+	for _, forged := range []struct {
+		Tenant   string
+		BannerId int32
+	}{{"team_a", 43}, {"team_b", 42}} {
+		forgedToken, _, _ := preview.Sign(forged.Tenant, forged.BannerId, time.Minute)
+		forgedParts := strings.Split(forgedToken, ".")
+		if _, _, err := preview.Verify(forgedParts[0] + "." + parts[1]); err != preview.ErrInvalidToken {
+			t.Fatalf("Expected invalid token error; got %v", err)
+		}
+	}
+
+	expired, _, _ := preview.Sign("team_a", 42, -time.Second)
+	if _, _, err := preview.Verify(expired); err != preview.ErrExpiredToken {
+		t.Fatalf("Expected expired token error; got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/user_banner?preview_token="+expired, nil)
+	w := httptest.NewRecorder()
+	server.Wrapper.UserBannerGet(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status %v; got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
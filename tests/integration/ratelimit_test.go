@@ -0,0 +1,138 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"my_app/internal/auth"
+	"my_app/internal/config"
+	"my_app/internal/ratelimit"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+type rateLimitTestsuite struct {
+	Name   string
+	Path   string
+	Token  string
+	Remote string
+	Code   int
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ratelimit.InitRateLimit(config.RateLimit{Routes: "UserBannerGet=2/1m"})
+	defer ratelimit.InitRateLimit(config.RateLimit{})
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+	token := func(subject string) string {
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": subject, "role": "user", "exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("test_secret"))
+		return signed
+	}
+	alice, bob := token("alice"), token("bob")
+
+	// Запросы без валидного токена считаются по адресу клиента, поэтому новый токен
+	// на каждый запрос лимит не обходит. Запросы без feature_id отклоняются до обращения к базе данных
+	testsuite := []rateLimitTestsuite{
+		{Name: "First bogus token", Path: "/user_banner", Token: "bogus_1", Remote: "10.0.0.1:1000", Code: http.StatusUnauthorized},
+		{Name: "Second bogus token", Path: "/user_banner", Token: "bogus_2", Remote: "10.0.0.1:1001", Code: http.StatusUnauthorized},
+		{Name: "Rotated bogus token", Path: "/user_banner", Token: "bogus_3", Remote: "10.0.0.1:1002", Code: http.StatusTooManyRequests},
+		{Name: "No token", Path: "/user_banner", Remote: "10.0.0.1:1003", Code: http.StatusTooManyRequests},
+		{Name: "Other address", Path: "/user_banner", Token: "bogus_4", Remote: "10.0.0.2:1000", Code: http.StatusUnauthorized},
+		{Name: "Valid token", Path: "/user_banner", Token: alice, Remote: "10.0.0.1:1004", Code: http.StatusBadRequest},
+		{Name: "Valid token again", Path: "/user_banner", Token: alice, Remote: "10.0.0.3:1000", Code: http.StatusBadRequest},
+		{Name: "Valid token over limit", Path: "/user_banner", Token: alice, Remote: "10.0.0.4:1000", Code: http.StatusTooManyRequests},
+		{Name: "Other subject", Path: "/user_banner", Token: bob, Remote: "10.0.0.1:1005", Code: http.StatusBadRequest},
+		{Name: "Unlimited route", Path: "/banner", Token: "bogus_5", Remote: "10.0.0.1:1006", Code: http.StatusUnauthorized},
+	}
+
+	router := server.NewRouter()
+	for _, curTest := range testsuite {
+		req := httptest.NewRequest(http.MethodGet, curTest.Path, nil)
+		req.RemoteAddr = curTest.Remote
+		if curTest.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+curTest.Token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != curTest.Code {
+			t.Fatalf("%s: expected status %v; got %v", curTest.Name, curTest.Code, w.Code)
+		}
+		if curTest.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+			t.Fatalf("%s: expected Retry-After header", curTest.Name)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	ctx := context.Background()
+	limiter := ratelimit.NewMemoryLimiter()
+	limit := ratelimit.Limit{Requests: 1, Period: 50 * time.Millisecond}
+
+	if allowed, _, _ := limiter.Allow(ctx, "key", limit); !allowed {
+		t.Fatalf("Expected first request to be allowed")
+	}
+	allowed, retryAfter, _ := limiter.Allow(ctx, "key", limit)
+	if allowed || retryAfter <= 0 || retryAfter > limit.Period {
+		t.Fatalf("Expected request to be limited for up to %v; got %v, %v", limit.Period, allowed, retryAfter)
+	}
+	time.Sleep(retryAfter + 10*time.Millisecond)
+	if allowed, _, _ := limiter.Allow(ctx, "key", limit); !allowed {
+		t.Fatalf("Expected bucket to refill")
+	}
+
+	for _, value := range []string{"10", "0/1s", "10/0s", "x/1m", "10/minute"} {
+		if _, err := ratelimit.ParseLimit(value); err == nil {
+			t.Fatalf("Expected error for limit %q", value)
+		}
+	}
+}
+
+func TestMemoryLimiterEviction(t *testing.T) {
+	ctx := context.Background()
+	limiter := ratelimit.NewMemoryLimiter()
+	long := ratelimit.Limit{Requests: 1, Period: time.Hour}
+	short := ratelimit.Limit{Requests: 1, Period: time.Millisecond}
+
+	// Исчерпанная корзина с долгим периодом переживает вытеснение при запросах на маршрут с коротким периодом
+	limiter.Allow(ctx, "long", long)
+	for i := 1; i < ratelimit.MaxMemoryBuckets; i++ {
+		limiter.Allow(ctx, fmt.Sprint("short_", i), short)
+	}
+	time.Sleep(5 * time.Millisecond)
+	limiter.Allow(ctx, "short_new", short)
+	if allowed, _, _ := limiter.Allow(ctx, "long", long); allowed {
+		t.Fatalf("Expected long period bucket to survive eviction")
+	}
+	if limiter.Len() > ratelimit.MaxMemoryBuckets {
+		t.Fatalf("Expected at most %d buckets; got %d", ratelimit.MaxMemoryBuckets, limiter.Len())
+	}
+	t.Log("Long period bucket kept : Pass")
+
+	// Если наполнившихся корзин нет, удаляется самая давно не использованная
+	limiter = ratelimit.NewMemoryLimiter()
+	for i := 0; i < ratelimit.MaxMemoryBuckets; i++ {
+		limiter.Allow(ctx, fmt.Sprint("long_", i), long)
+	}
+	limiter.Allow(ctx, "long_0", long)
+	limiter.Allow(ctx, "long_new", long)
+	if limiter.Len() != ratelimit.MaxMemoryBuckets {
+		t.Fatalf("Expected %d buckets; got %d", ratelimit.MaxMemoryBuckets, limiter.Len())
+	}
+	if allowed, _, _ := limiter.Allow(ctx, "long_0", long); allowed {
+		t.Fatalf("Expected recently used bucket to be kept")
+	}
+	if allowed, _, _ := limiter.Allow(ctx, "long_1", long); !allowed {
+		t.Fatalf("Expected least recently used bucket to be evicted")
+	}
+	t.Log("Least recently used bucket evicted : Pass")
+}
@@ -0,0 +1,125 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"my_app/internal/auth"
+	"my_app/internal/config"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+type rbacTestsuite struct {
+	Name   string
+	Method string
+	Path   string
+	Role   string
+	Scope  string
+	Code   int
+}
+
+func TestFeaturePermissions(t *testing.T) {
+	rolesPath := filepath.Join(t.TempDir(), "roles.json")
+	err := os.WriteFile(rolesPath, []byte(`{"team_a_editor": ["banner:read", "banner:list@1,2", "banner:write@1,2"]}`), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write roles: %v", err)
+	}
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{RolesFile: rolesPath})
+
+	// Отказ по фиче происходит до обращения к базе данных
+	testsuite := []rbacTestsuite{
+		{Name: "Route scope missing", Method: http.MethodGet, Path: "/audit", Role: "team_a_editor", Code: http.StatusForbidden},
+		{Name: "Listing foreign feature", Method: http.MethodGet, Path: "/banner?feature_id=3", Role: "team_a_editor", Code: http.StatusForbidden},
+		{Name: "Listing without feature", Method: http.MethodGet, Path: "/banner?tag_id=1", Role: "team_a_editor", Code: http.StatusForbidden},
+		{Name: "Export without feature", Method: http.MethodGet, Path: "/banner/export", Role: "team_a_editor", Code: http.StatusForbidden},
+		{Name: "Schema of foreign feature", Method: http.MethodGet, Path: "/feature/3/schema", Scope: "schema:write@1", Code: http.StatusForbidden},
+		{Name: "User banner of foreign feature", Method: http.MethodGet, Path: "/user_banner?feature_id=3&tag_id=1", Scope: "banner:read@1", Code: http.StatusForbidden},
+		{Name: "Scope from token", Method: http.MethodGet, Path: "/feature/abc/schema", Scope: "schema:write", Code: http.StatusBadRequest},
+		{Name: "Preview by editor", Method: http.MethodPost, Path: "/banner/1/preview", Role: "editor", Code: http.StatusForbidden},
+		{Name: "Preview by approver", Method: http.MethodPost, Path: "/banner/1/preview", Role: "approver", Code: http.StatusForbidden},
+		{Name: "Preview scope", Method: http.MethodPost, Path: "/banner/abc/preview", Scope: "banner:preview", Code: http.StatusBadRequest},
+		{Name: "Unknown role", Method: http.MethodGet, Path: "/user_banner", Role: "team_b_editor", Code: http.StatusForbidden},
+	}
+
+	router := server.NewRouter()
+	for _, curTest := range testsuite {
+		claims := jwt.MapClaims{"sub": "tester", "exp": time.Now().Add(time.Hour).Unix()}
+		if curTest.Role != "" {
+			claims["role"] = curTest.Role
+		}
+		if curTest.Scope != "" {
+			claims["scope"] = curTest.Scope
+		}
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test_secret"))
+		req := httptest.NewRequest(curTest.Method, curTest.Path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		res := w.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != curTest.Code {
+			t.Fatalf("%s: expected status %v; got %v", curTest.Name, curTest.Code, res.StatusCode)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
+
+func TestParseGrant(t *testing.T) {
+	rbac.InitRBAC(config.RBAC{})
+	permissions := rbac.ForPrincipal(&auth.Principal{Roles: []string{"editor"}, Scopes: []string{"banner:delete@7", "unknown:scope"}})
+	if !permissions.AllowsFeature(rbac.ScopeBannerWrite, 100) {
+		t.Fatalf("Expected editor to write any feature")
+	}
+	if !permissions.AllowsFeature(rbac.ScopeBannerDelete, 7) || permissions.AllowsFeature(rbac.ScopeBannerDelete, 8) {
+		t.Fatalf("Expected delete to be limited to feature 7")
+	}
+	if features, all := permissions.Features(rbac.ScopeBannerDelete); all || len(features) != 1 || features[0] != 7 {
+		t.Fatalf("Expected delete features [7]; got %v, %v", features, all)
+	}
+	if _, err := rbac.ParseGrant("banner:write@x"); err == nil {
+		t.Fatalf("Expected invalid feature id error")
+	}
+	if _, err := rbac.ParseGrant("banner:fly"); err == nil {
+		t.Fatalf("Expected unknown scope error")
+	}
+}
+
+func TestPermissionsCovers(t *testing.T) {
+	rbac.InitRBAC(config.RBAC{})
+	testsuite := []struct {
+		Name      string
+		Held      []string
+		Requested []string
+		Covers    bool
+	}{
+		{Name: "Same scope", Held: []string{"banner:read"}, Requested: []string{"banner:read"}, Covers: true},
+		{Name: "Missing scope", Held: []string{"banner:read"}, Requested: []string{"banner:write"}},
+		{Name: "Feature subset", Held: []string{"banner:write@1,2"}, Requested: []string{"banner:write@2"}, Covers: true},
+		{Name: "Foreign feature", Held: []string{"banner:write@1,2"}, Requested: []string{"banner:write@3"}},
+		{Name: "All features by limited grant", Held: []string{"banner:write@1"}, Requested: []string{"banner:write"}},
+		{Name: "Everything by all", Held: []string{"*"}, Requested: []string{"*", "banner:write@1"}, Covers: true},
+		{Name: "All by separate scopes", Held: []string{"banner:read", "banner:write", "apikey:manage"}, Requested: []string{"*"}},
+	}
+	for _, curTest := range testsuite {
+		held, _ := rbac.ParseGrants(curTest.Held)
+		requested, _ := rbac.ParseGrants(curTest.Requested)
+		if covers := held.Covers(requested); covers != curTest.Covers {
+			t.Fatalf("%s: expected covers %v; got %v", curTest.Name, curTest.Covers, covers)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+	// Права роли сравниваются так же, как scopes
+	if !rbac.RolePermissions("admin").Covers(rbac.RolePermissions("editor")) || rbac.RolePermissions("editor").Covers(rbac.RolePermissions("approver")) {
+		t.Fatalf("Unexpected role coverage")
+	}
+	t.Log("Role permissions : Pass")
+}
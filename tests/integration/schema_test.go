@@ -0,0 +1,69 @@
+package server_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"my_app/internal/models"
+	"my_app/internal/schema"
+)
+
+type contentSchemaTestsuite struct {
+	Name    string
+	Content models.ModelMap
+	Fields  []string
+}
+
+func TestValidateContent(t *testing.T) {
+	contentSchema, err := schema.Compile([]byte(`{
+		"type": "object",
+		"required": ["title", "url"],
+		"properties": {
+			"title": {"type": "string", "minLength": 1},
+			"url": {"type": "string"},
+			"priority": {"type": "integer"}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to compile schema: %v", err)
+	}
+
+	testsuite := []contentSchemaTestsuite{
+		{
+			Name:    "Correct",
+			Content: models.ModelMap{"title": "some_title", "url": "some_url"},
+		},
+		{
+			Name:    "Missing url",
+			Content: models.ModelMap{"title": "some_title"},
+			Fields:  []string{"/content"},
+		},
+		{
+			Name:    "Wrong types",
+			Content: models.ModelMap{"title": "", "url": "some_url", "priority": "high"},
+			Fields:  []string{"/content/priority", "/content/title"},
+		},
+	}
+
+	for _, curTest := range testsuite {
+		fieldErrors, err := schema.ValidateContent(contentSchema, curTest.Content)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", curTest.Name, err)
+		}
+		var fields []string
+		for _, fieldError := range fieldErrors {
+			fields = append(fields, fieldError.Field)
+		}
+		sort.Strings(fields)
+		if !reflect.DeepEqual(fields, curTest.Fields) {
+			t.Fatalf("%s: expected errors in %v; got %+v", curTest.Name, curTest.Fields, fieldErrors)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+
+	_, err = schema.Compile([]byte(`{"$ref": "file:///etc/passwd"}`))
+	if err == nil {
+		t.Fatalf("Expected external reference to be rejected")
+	}
+}
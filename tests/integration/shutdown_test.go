@@ -0,0 +1,105 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"my_app/internal/background"
+	"my_app/internal/server"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	cfg := testConfig(t)
+	server.InitServer(cfg.Server, cfg.Features)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + listener.Addr().String()
+
+	started := make(chan struct{})
+	var taskDone atomic.Bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Фоновая задача, например запись в кэш, переживает запрос
+		background.Go(r.Context(), func(ctx context.Context) {
+			time.Sleep(200 * time.Millisecond)
+			taskDone.Store(ctx.Err() == nil)
+		})
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, listener, handler)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{string(body), err}
+	}()
+
+	<-started
+	cancel()
+
+	res := <-responses
+	if res.err != nil || res.body != "done" {
+		t.Fatalf("Expected in-flight request to complete; got %q, %v", res.body, res.err)
+	}
+	t.Log("In-flight request drained : Pass")
+
+	if err := <-served; err != nil {
+		t.Fatalf("Expected clean shutdown; got %v", err)
+	}
+	if !taskDone.Load() {
+		t.Fatalf("Expected shutdown to wait for background task")
+	}
+	t.Log("Background task awaited : Pass")
+
+	if _, err := http.Get(url); err == nil {
+		t.Fatalf("Expected new connections to be refused after shutdown")
+	}
+	t.Log("New connections refused : Pass")
+}
+
+func TestPeriodicTaskStopsOnShutdown(t *testing.T) {
+	var runs atomic.Int32
+	background.Every(context.Background(), 10*time.Millisecond, func(ctx context.Context) {
+		runs.Add(1)
+	})
+	time.Sleep(50 * time.Millisecond)
+	if runs.Load() == 0 {
+		t.Fatalf("Expected periodic task to run")
+	}
+
+	// Периодическая задача не должна задерживать остановку до таймаута
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := background.Wait(ctx); err != nil {
+		t.Fatalf("Expected periodic task to stop; got %v", err)
+	}
+	stopped := runs.Load()
+	time.Sleep(50 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Fatalf("Expected periodic task not to run after shutdown")
+	}
+	t.Log("Periodic task stopped : Pass")
+}
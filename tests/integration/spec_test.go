@@ -0,0 +1,90 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/codegen"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/util"
+	"gopkg.in/yaml.v3"
+
+	"my_app/internal/server"
+)
+
+func TestGeneratedCodeUpToDate(t *testing.T) {
+	// Тот же конфиг, что читает go generate ./api
+	data, err := os.ReadFile("../../api/oapi-codegen.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read generator config: %v", err)
+	}
+	var cfg struct {
+		codegen.Configuration `yaml:",inline"`
+		Output                string `yaml:"output"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Failed to parse generator config: %v", err)
+	}
+	cfg.Configuration = cfg.UpdateDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Invalid generator config: %v", err)
+	}
+
+	swagger, err := util.LoadSwagger("../../api/api.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load api spec: %v", err)
+	}
+	expected, err := codegen.Generate(swagger, cfg.Configuration)
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+	actual, err := os.ReadFile("../../api/" + cfg.Output)
+	if err != nil {
+		t.Fatalf("Failed to read generated code: %v", err)
+	}
+	// Версия генератора в заголовке берется из сборки, поэтому не сравнивается
+	header := regexp.MustCompile(`(?m)^// Code generated by .*$`)
+	if header.ReplaceAllString(string(actual), "") != header.ReplaceAllString(expected, "") {
+		t.Fatalf("api/%s is out of date with api/api.yaml, run go generate ./api", cfg.Output)
+	}
+	t.Log("Generated code : Pass")
+}
+
+func TestRouterMatchesSpec(t *testing.T) {
+	swagger, err := openapi3.NewLoader().LoadFromFile("../../api/api.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load api spec: %v", err)
+	}
+	pathParam := regexp.MustCompile(`\{[^}]+\}`)
+	router := server.NewRouter()
+
+	// Каждая операция спецификации обслуживается маршрутом с именем ее operationId
+	for path, pathItem := range swagger.Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			req := httptest.NewRequest(method, pathParam.ReplaceAllString(path, "1"), nil)
+			var match mux.RouteMatch
+			if !router.Match(req, &match) || match.Route == nil {
+				t.Fatalf("%s %s: route not found", method, path)
+			}
+			if match.Route.GetName() != operation.OperationID {
+				t.Fatalf("%s %s: expected route %q; got %q", method, path, operation.OperationID, match.Route.GetName())
+			}
+			t.Log(operation.OperationID, ": Pass")
+		}
+	}
+
+	// Пути вне спецификации не перенаправляются и не обслуживаются
+	for _, path := range []string{"/", "/banner/"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected status %d; got %d", path, http.StatusNotFound, w.Code)
+		}
+		t.Log(path, ": Pass")
+	}
+}
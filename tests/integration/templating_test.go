@@ -0,0 +1,123 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"my_app/internal/config"
+	"my_app/internal/db"
+	"my_app/internal/logging"
+	"my_app/internal/models"
+	"my_app/internal/templating"
+)
+
+type templateRenderTestsuite struct {
+	Name   string
+	Query  map[string][]string
+	Output models.ModelMap
+}
+
+func TestTemplating(t *testing.T) {
+	templating.InitTemplating(config.Templating{Vars: []string{"name", "city"}})
+
+	content := models.ModelMap{
+		"title": "Hi {{name|friend}}, 20% off in {{ city }}",
+		"url":   "some_url",
+		"items": []interface{}{"{{name}}", float64(1)},
+	}
+	fields, fieldErrors := templating.ParseContent(content)
+	if len(fieldErrors) > 0 {
+		t.Fatalf("Unexpected template errors %v", fieldErrors)
+	}
+
+	testsuite := []templateRenderTestsuite{
+		{
+			Name:  "All variables",
+			Query: map[string][]string{"name": {"Anna"}, "city": {"Kazan"}, "tag_id": {"1"}},
+			Output: models.ModelMap{
+				"title": "Hi Anna, 20% off in Kazan",
+				"url":   "some_url",
+				"items": []interface{}{"Anna", float64(1)},
+			},
+		},
+		{
+			Name:  "Default value",
+			Query: map[string][]string{"city": {"Kazan"}},
+			Output: models.ModelMap{
+				"title": "Hi friend, 20% off in Kazan",
+				"url":   "some_url",
+				"items": []interface{}{"", float64(1)},
+			},
+		},
+	}
+	for _, curTest := range testsuite {
+		rendered := templating.Render(content, fields, templating.Vars(curTest.Query))
+		if !reflect.DeepEqual(map[string]interface{}(rendered), map[string]interface{}(curTest.Output)) {
+			t.Fatalf("%s: expected %v; got %v", curTest.Name, curTest.Output, rendered)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+	if content["title"] != "Hi {{name|friend}}, 20% off in {{ city }}" {
+		t.Fatalf("Render must not modify source content")
+	}
+
+	invalid := models.ModelMap{
+		"a": "{{name",
+		"b": "{{phone}}",
+		"c": "{{ na-me }}",
+		"d": "text }}",
+	}
+	_, fieldErrors = templating.ParseContent(invalid)
+	var errorFields []string
+	for _, fieldError := range fieldErrors {
+		errorFields = append(errorFields, fieldError.Field)
+	}
+	if !reflect.DeepEqual(errorFields, []string{"/content/a", "/content/b", "/content/c", "/content/d"}) {
+		t.Fatalf("Unexpected template errors %v", fieldErrors)
+	}
+}
+
+func TestParseStoredTemplates(t *testing.T) {
+	templating.InitTemplating(config.Templating{Vars: []string{"name"}})
+
+	// Лог пишется в stdout, на время теста он перехватывается
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = writer
+	logging.InitLogging(config.Logging{Level: "info"})
+	defer func() {
+		os.Stdout = stdout
+		logging.InitLogging(config.Logging{Level: "info"})
+	}()
+
+	// Баннер сохранен, когда переменная city еще была разрешена
+	banner := &models.LocalizedBanner{BannerExpanded: models.BannerExpanded{ID: 42, Content: models.ModelMap{
+		"title": "Hi {{name}}",
+		"text":  "Sale in {{city}}",
+	}}}
+	db.ParseTemplates(context.Background(), banner)
+	writer.Close()
+	os.Stdout = stdout
+	output, _ := io.ReadAll(reader)
+
+	if len(banner.Templates) != 1 || strings.Join(banner.Templates[0].Path, "/") != "title" {
+		t.Fatalf("Expected only valid template to be parsed; got %+v", banner.Templates)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(output, &record); err != nil {
+		t.Fatalf("Expected one log record; got %s", output)
+	}
+	if record["banner_id"] != float64(42) || record["field"] != "/content/text" || !strings.Contains(fmt.Sprint(record["error"]), "city") {
+		t.Fatalf("Expected log with banner id and field; got %s", output)
+	}
+	t.Log("Invalid stored template : Pass")
+}
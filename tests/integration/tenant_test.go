@@ -0,0 +1,162 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"my_app/internal/auth"
+	"my_app/internal/cache"
+	"my_app/internal/config"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+func tenantToken(tenant interface{}, role string) string {
+	claims := jwt.MapClaims{"sub": "tester", "role": role, "exp": time.Now().Add(time.Hour).Unix()}
+	if tenant != nil {
+		claims["tenant"] = tenant
+	}
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test_secret"))
+	return token
+}
+
+func TestTenantClaim(t *testing.T) {
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+
+	testsuite := []struct {
+		Name   string
+		Tenant interface{}
+		Want   string
+		Err    error
+	}{
+		{Name: "Tenant claim", Tenant: "team_a", Want: "team_a"},
+		{Name: "No tenant claim", Tenant: nil, Want: auth.DefaultTenant},
+		{Name: "Separator in tenant", Tenant: "team_a:1", Err: auth.ErrInvalidTenant},
+		{Name: "Upper case tenant", Tenant: "TeamA", Err: auth.ErrInvalidTenant},
+		{Name: "Tenant is not a string", Tenant: 7, Err: auth.ErrInvalidTenant},
+	}
+	for _, curTest := range testsuite {
+		principal, err := auth.Verify(tenantToken(curTest.Tenant, "user"))
+		if curTest.Err != nil {
+			if !errors.Is(err, curTest.Err) {
+				t.Fatalf("%s: expected error %v; got %v", curTest.Name, curTest.Err, err)
+			}
+		} else if err != nil || principal.Tenant != curTest.Want {
+			t.Fatalf("%s: expected tenant %v; got %v, %v", curTest.Name, curTest.Want, principal, err)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+
+	// Инициализация тестовой базы данных и кэша
+	ctx := context.Background()
+	cfg := testConfig(t)
+	db.InitDB(cfg.Database)
+	defer db.CloseDB()
+	cache.InitCache(cfg.Cache)
+	defer cache.CloseCache()
+
+	// У тенантов одинаковые фича и тег, но разное содержимое
+	featureId := int(time.Now().UnixNano() % 1000000)
+	tagId := featureId + 1
+	tenants := []string{"tenant_a", "tenant_b"}
+	bannerIds := make(map[string]int)
+	for _, tenant := range tenants {
+		banner := models.BannerNoId{
+			TagIds:    []int32{int32(tagId)},
+			FeatureId: int32(featureId),
+			Content:   map[string]interface{}{"owner": tenant},
+			IsActive:  true,
+		}
+		bannerId, err := db.CreateBanner(ctx, tenant, banner, "test")
+		if err != nil {
+			t.Fatalf("Failed to create banner: %v", err)
+		}
+		for _, action := range []models.TransitionAction{models.ActionSubmit, models.ActionApprove, models.ActionPublish} {
+			_, err = db.TransitionBanner(ctx, tenant, int(bannerId), action, "test", "")
+			if err != nil {
+				t.Fatalf("Failed to %s banner: %v", action, err)
+			}
+		}
+		bannerIds[tenant] = int(bannerId)
+	}
+
+	// Второе чтение идет из кэша, ключи тенантов не должны пересекаться
+	for i := 0; i < 2; i++ {
+		for _, tenant := range tenants {
+			banner, err := db.GetBannerForUser(ctx, tenant, &featureId, &tagId, nil, false, false)
+			if err != nil {
+				t.Fatalf("Failed to get banner of %s: %v", tenant, err)
+			}
+			if banner.Content["owner"] != tenant {
+				t.Fatalf("Tenant %s got banner of %v", tenant, banner.Content["owner"])
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Log("User banner per tenant : Pass")
+
+	foreignId := bannerIds["tenant_a"]
+	if _, err := db.GetBannerFeatureIds(ctx, "tenant_b", foreignId); !errors.Is(err, db.ErrBannerNotFound) {
+		t.Fatalf("Expected foreign banner to be not found; got %v", err)
+	}
+	if _, err := db.GetBannerHistory(ctx, "tenant_b", foreignId); !errors.Is(err, db.ErrBannerNotFound) {
+		t.Fatalf("Expected foreign history to be not found; got %v", err)
+	}
+	if _, err := db.GetBannerPreview(ctx, "tenant_b", foreignId); !errors.Is(err, db.ErrBannerNotFound) {
+		t.Fatalf("Expected foreign preview to be not found; got %v", err)
+	}
+	if err := db.UpdateBanner(ctx, "tenant_b", foreignId, models.BannerNoId{FeatureId: int32(featureId)}, "test"); !errors.Is(err, db.ErrBannerNotFound) {
+		t.Fatalf("Expected foreign update to fail; got %v", err)
+	}
+	if _, err := db.TransitionBanner(ctx, "tenant_b", foreignId, models.ActionSubmit, "test", ""); !errors.Is(err, db.ErrBannerNotFound) {
+		t.Fatalf("Expected foreign transition to fail; got %v", err)
+	}
+	if err := db.DeleteBanner(ctx, "tenant_b", foreignId, "test"); !errors.Is(err, db.ErrBannerNotFound) {
+		t.Fatalf("Expected foreign delete to fail; got %v", err)
+	}
+	t.Log("Foreign banner by id : Pass")
+
+	banners, err := db.GetBanners(ctx, "tenant_b", &featureId, nil, nil, nil)
+	if err != nil || len(banners) != 1 || int(banners[0].ID) != bannerIds["tenant_b"] {
+		t.Fatalf("Expected only own banner in list; got %v, %v", banners, err)
+	}
+	records, err := db.GetAuditLog(ctx, "tenant_b", models.AuditFilter{BannerId: &foreignId, Limit: 10})
+	if err != nil || len(records) != 0 {
+		t.Fatalf("Expected no foreign audit records; got %v, %v", records, err)
+	}
+	t.Log("Lists and audit : Pass")
+
+	// Через API чужой баннер выглядит несуществующим
+	router := server.NewRouter()
+	for _, path := range []string{
+		fmt.Sprintf("/banner/%d/history", foreignId),
+		fmt.Sprintf("/user_banner?feature_id=%d&tag_id=%d&use_last_revision=true", featureId, tagId),
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+tenantToken("tenant_c", "admin"))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected status %v; got %v", path, http.StatusNotFound, w.Code)
+		}
+	}
+	t.Log("Foreign tenant through API : Pass")
+
+	for _, tenant := range tenants {
+		db.DeleteBanner(ctx, tenant, bannerIds[tenant], "test")
+	}
+}
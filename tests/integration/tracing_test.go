@@ -0,0 +1,62 @@
+package server_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"my_app/internal/config"
+	"my_app/internal/server"
+	"my_app/internal/tracing"
+)
+
+func TestTracePropagation(t *testing.T) {
+	const traceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	path := filepath.Join(t.TempDir(), "traces.json")
+	tracing.InitTracing(config.Tracing{Exporter: tracing.ExporterFile, File: path})
+
+	router := server.NewRouter()
+	req := httptest.NewRequest(http.MethodGet, "/user_banner", nil)
+	req.Header.Set("traceparent", "00-"+traceId+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	tracing.CloseTracing()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open trace file: %v", err)
+	}
+	defer file.Close()
+
+	type span struct {
+		Name        string
+		SpanContext struct{ TraceID string }
+		Parent      struct {
+			TraceID string
+			Remote  bool
+		}
+	}
+	var spans []span
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var s span
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatalf("Failed to decode span: %v", err)
+		}
+		spans = append(spans, s)
+	}
+
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span; got %d", len(spans))
+	}
+	if spans[0].Name != "GET /user_banner" {
+		t.Errorf("Expected span name %q; got %q", "GET /user_banner", spans[0].Name)
+	}
+	if spans[0].SpanContext.TraceID != traceId || !spans[0].Parent.Remote {
+		t.Errorf("Expected span to continue remote trace %s; got %s", traceId, spans[0].SpanContext.TraceID)
+	}
+	t.Log("W3C trace context : Pass")
+}
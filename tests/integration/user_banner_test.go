@@ -1,16 +1,20 @@
 package server_test
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"testing"
 
+	"my_app/internal/auth"
 	"my_app/internal/cache"
 	"my_app/internal/db"
 	"my_app/internal/models"
+	"my_app/internal/rbac"
 	"my_app/internal/server"
 )
 
@@ -48,6 +52,10 @@ func TestUserBannerGet(t *testing.T) {
 			},
 			Output: userBannerGetOutput{
 				Code: http.StatusNotFound,
+				Body: map[string]interface{}{
+					"code":  models.ErrorCodeNotFound,
+					"error": "no banner found",
+				},
 			},
 		},
 		{
@@ -59,7 +67,8 @@ func TestUserBannerGet(t *testing.T) {
 			Output: userBannerGetOutput{
 				Code: http.StatusBadRequest,
 				Body: map[string]interface{}{
-					"error": "tag_id and feature_id are required",
+					"code":  models.ErrorCodeBadRequest,
+					"error": "Invalid feature_id value",
 				},
 			},
 		},
@@ -72,6 +81,7 @@ func TestUserBannerGet(t *testing.T) {
 			Output: userBannerGetOutput{
 				Code: http.StatusBadRequest,
 				Body: map[string]interface{}{
+					"code":  models.ErrorCodeBadRequest,
 					"error": "tag_id and feature_id are required",
 				},
 			},
@@ -92,19 +102,42 @@ func TestUserBannerGet(t *testing.T) {
 	}
 
 	// Инициализация тестовой базы данных и кэша
-	db.InitDB()
+	ctx := context.Background()
+	cfg := testConfig(t)
+	db.InitDB(cfg.Database)
 	t.Log("Сonnected to db")
 	defer db.CloseDB()
-	cache.InitCache()
+	cache.InitCache(cfg.Cache)
 	t.Log("Сonnected to cache")
 	defer cache.CloseCache()
-	db.CreateBanner(banner)
+	bannerId, err := db.CreateBanner(ctx, auth.DefaultTenant, banner, "test")
+	if err != nil {
+		t.Fatalf("Failed to create banner: %v", err)
+	}
+	// Пользователям виден только опубликованный баннер
+	for _, action := range []models.TransitionAction{models.ActionSubmit, models.ActionApprove, models.ActionPublish} {
+		_, err = db.TransitionBanner(ctx, auth.DefaultTenant, int(bannerId), action, "test", "")
+		if err != nil {
+			t.Fatalf("Failed to %s banner: %v", action, err)
+		}
+	}
 
 	for _, curTest := range testsuite {
-		curUrl := "/user_banner?feature_id=" + curTest.Request.FeatureId + "&tag_id=" + curTest.Request.TagId
+		// Пустой параметр не передается
+		query := url.Values{}
+		if curTest.Request.FeatureId != "" {
+			query.Set("feature_id", curTest.Request.FeatureId)
+		}
+		if curTest.Request.TagId != "" {
+			query.Set("tag_id", curTest.Request.TagId)
+		}
+		curUrl := "/user_banner?" + query.Encode()
 		req := httptest.NewRequest(http.MethodGet, curUrl, nil)
+		// Права пользователя, которые выставляет AuthMiddleware
+		permissions := rbac.Permissions{{Scope: rbac.ScopeBannerRead}}
+		req = req.WithContext(context.WithValue(req.Context(), server.PermissionsKey, permissions))
 		w := httptest.NewRecorder()
-		server.UserBannerGet(w, req)
+		server.Wrapper.UserBannerGet(w, req)
 		res := w.Result()
 		defer res.Body.Close()
 
@@ -0,0 +1,192 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"my_app/internal/auth"
+	"my_app/internal/config"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+func TestRequestValidation(t *testing.T) {
+	cfg := testConfig(t)
+	defer server.InitServer(cfg.Server, cfg.Features)
+	// Ответы тоже проверяются, ошибки валидации должны соответствовать спецификации
+	validated := cfg.Server
+	validated.ValidateRequests = true
+	validated.ValidateResponses = true
+	server.InitServer(validated, cfg.Features)
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "tester", "role": "admin", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test_secret"))
+
+	// Запросы отклоняются до обращения к базе данных
+	testsuite := []struct {
+		Name   string
+		Method string
+		Path   string
+		Body   string
+		Status int
+		Code   string
+		Field  string
+	}{
+		{Name: "Negative limit", Method: http.MethodGet, Path: "/banner?limit=-1", Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "limit"},
+		{Name: "Negative offset", Method: http.MethodGet, Path: "/banner?offset=-5", Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "offset"},
+		{Name: "Invalid tag_id", Method: http.MethodGet, Path: "/user_banner?tag_id=x&feature_id=1", Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "tag_id"},
+		{Name: "Unknown field", Method: http.MethodPost, Path: "/banner", Body: `{"tag_ids": [1], "feature_id": 1, "content": {}, "is_active": true, "priority": 1}`, Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: ""},
+		{Name: "Empty tag_ids", Method: http.MethodPost, Path: "/banner", Body: `{"tag_ids": [], "feature_id": 1, "content": {}, "is_active": true}`, Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "/tag_ids"},
+		{Name: "Missing feature_id", Method: http.MethodPost, Path: "/banner", Body: `{"tag_ids": [1], "content": {}}`, Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "/feature_id"},
+		{Name: "Empty body object", Method: http.MethodPost, Path: "/banner", Body: `{}`, Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "/tag_ids"},
+		{Name: "Negative feature_id", Method: http.MethodPost, Path: "/banner", Body: `{"tag_ids": [1], "feature_id": -1, "content": {}}`, Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "/feature_id"},
+		{Name: "Negative tag id", Method: http.MethodPost, Path: "/banner", Body: `{"tag_ids": [-1], "feature_id": 1, "content": {}}`, Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "/tag_ids/0"},
+		{Name: "Wrong type", Method: http.MethodPatch, Path: "/banner/1", Body: `{"feature_id": "one"}`, Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "/feature_id"},
+		{Name: "Unknown action", Method: http.MethodPost, Path: "/banner/1/transitions", Body: `{"action": "archive"}`, Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "/action"},
+		{Name: "Invalid path id", Method: http.MethodGet, Path: "/feature/abc/schema", Status: http.StatusBadRequest, Code: models.ErrorCodeValidation, Field: "id"},
+	}
+
+	router := server.NewRouter()
+	for _, curTest := range testsuite {
+		req := httptest.NewRequest(curTest.Method, curTest.Path, strings.NewReader(curTest.Body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if curTest.Body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != curTest.Status {
+			t.Fatalf("%s: expected status %d; got %d: %s", curTest.Name, curTest.Status, w.Code, w.Body.String())
+		}
+		var response models.ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: failed to decode error: %v", curTest.Name, err)
+		}
+		if response.Code != curTest.Code || len(response.Fields) == 0 {
+			t.Fatalf("%s: expected code %q with fields; got %+v", curTest.Name, curTest.Code, response)
+		}
+		if response.Fields[0].Field != curTest.Field || response.Fields[0].Error == "" {
+			t.Fatalf("%s: expected error for field %q; got %+v", curTest.Name, curTest.Field, response.Fields)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
+
+func TestResponseValidation(t *testing.T) {
+	cfg := testConfig(t)
+	defer server.InitServer(cfg.Server, cfg.Features)
+	validated := cfg.Server
+	validated.ValidateResponses = true
+	server.InitServer(validated, cfg.Features)
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "tester", "role": "user", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test_secret"))
+
+	// Ответ обработчика соответствует спецификации и проходит без изменений
+	req := httptest.NewRequest(http.MethodGet, "/user_banner?feature_id=1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	server.NewRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d; got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+	var response models.ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode error: %v", err)
+	}
+	if response.Code != models.ErrorCodeBadRequest {
+		t.Fatalf("Expected handler error %q; got %+v", models.ErrorCodeBadRequest, response)
+	}
+	t.Log("Valid response : Pass")
+
+	// Ответы, расходящиеся со спецификацией, заменяются на 500
+	route := server.Route{Name: "UserBannerGet", Method: http.MethodGet, Pattern: "/user_banner"}
+	offSpec := []struct {
+		Name   string
+		Status int
+		Body   string
+	}{
+		{Name: "Undocumented status", Status: http.StatusTeapot, Body: `{"code": "bad_request", "error": "teapot"}`},
+		{Name: "Error without code", Status: http.StatusBadRequest, Body: `{"error": "no code"}`},
+		{Name: "Unknown error code", Status: http.StatusNotFound, Body: `{"code": "missing", "error": "not found"}`},
+	}
+	for _, curTest := range offSpec {
+		handler := server.ValidateMiddleware(route)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			w.WriteHeader(curTest.Status)
+			w.Write([]byte(curTest.Body))
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/user_banner?feature_id=1", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("%s: expected status %d; got %d: %s", curTest.Name, http.StatusInternalServerError, w.Code, w.Body.String())
+		}
+		var response models.ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: failed to decode error: %v", curTest.Name, err)
+		}
+		if response.Code != models.ErrorCodeInternal || len(response.Fields) == 0 {
+			t.Fatalf("%s: expected code %q with fields; got %+v", curTest.Name, models.ErrorCodeInternal, response)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	cfg := testConfig(t)
+	defer server.InitServer(cfg.Server, cfg.Features)
+	validated := cfg.Server
+	validated.ValidateRequests = true
+	server.InitServer(validated, cfg.Features)
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "tester", "role": "admin", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test_secret"))
+
+	// Импорт принимает тела больше общего лимита, строки без feature_id отклоняются до обращения к базе данных
+	largeImport := strings.Repeat(`{"tag_ids":[1],"content":{}}`+"\n", 64*1024)
+	testsuite := []struct {
+		Name        string
+		Path        string
+		ContentType string
+		Body        string
+		TooLarge    bool
+	}{
+		{Name: "Large banner", Path: "/banner", ContentType: "application/json", Body: `{"content": "` + strings.Repeat("a", 2<<20) + `"}`, TooLarge: true},
+		{Name: "Large import", Path: "/banner/import?dry_run=true", ContentType: "application/x-ndjson", Body: largeImport},
+	}
+
+	router := server.NewRouter()
+	for _, curTest := range testsuite {
+		req := httptest.NewRequest(http.MethodPost, curTest.Path, strings.NewReader(curTest.Body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", curTest.ContentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status %d; got %d", curTest.Name, http.StatusBadRequest, w.Code)
+		}
+		tooLarge := strings.Contains(w.Body.String(), "request body too large")
+		if tooLarge != curTest.TooLarge {
+			t.Fatalf("%s: expected body limit error %v", curTest.Name, curTest.TooLarge)
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+}
@@ -0,0 +1,124 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"my_app/internal/auth"
+	"my_app/internal/cache"
+	"my_app/internal/config"
+	"my_app/internal/db"
+	"my_app/internal/models"
+	"my_app/internal/rbac"
+	"my_app/internal/server"
+)
+
+type workflowTestsuite struct {
+	Name   string
+	Role   string
+	Method string
+	Path   string
+	Body   string
+	Code   int
+	Status models.RevisionStatus
+}
+
+func TestBannerWorkflow(t *testing.T) {
+	auth.InitAuth(config.Auth{HS256Secret: "test_secret"})
+	rbac.InitRBAC(config.RBAC{})
+
+	// Инициализация тестовой базы данных и кэша
+	cfg := testConfig(t)
+	db.InitDB(cfg.Database)
+	defer db.CloseDB()
+	cache.InitCache(cfg.Cache)
+	defer cache.CloseCache()
+
+	const tenant = "workflow"
+	router := server.NewRouter()
+	request := func(role string, method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+tenantToken(tenant, role))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	featureId := int(time.Now().UnixNano() % 1000000)
+	w := request("editor", http.MethodPost, "/banner", fmt.Sprintf(`{"tag_ids": [1], "feature_id": %d, "content": {"title": "a"}, "is_active": true}`, featureId))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create banner: %v %s", w.Code, w.Body.String())
+	}
+	var created models.IdResponse
+	json.Unmarshal(w.Body.Bytes(), &created)
+	defer db.DeleteBanner(context.Background(), tenant, int(created.BannerId), "test")
+	banner := fmt.Sprintf("/banner/%d", created.BannerId)
+	transitions := banner + "/transitions"
+	userBanner := fmt.Sprintf("/user_banner?feature_id=%d&tag_id=1", featureId)
+
+	// Редактор готовит ревизию, согласующий утверждает и публикует. Переходы не из своего
+	// статуса отклоняются с 409, переходы без нужного права - с 403
+	testsuite := []workflowTestsuite{
+		{Name: "Draft is not visible", Role: "user", Method: http.MethodGet, Path: userBanner, Code: http.StatusNotFound},
+		{Name: "Approve draft", Role: "approver", Method: http.MethodPost, Path: transitions, Body: `{"action": "approve"}`, Code: http.StatusConflict},
+		{Name: "Publish draft", Role: "approver", Method: http.MethodPost, Path: transitions, Body: `{"action": "publish"}`, Code: http.StatusConflict},
+		{Name: "Withdraw draft", Role: "editor", Method: http.MethodPost, Path: transitions, Body: `{"action": "withdraw"}`, Code: http.StatusConflict},
+		{Name: "Submit by approver", Role: "approver", Method: http.MethodPost, Path: transitions, Body: `{"action": "submit"}`, Code: http.StatusForbidden},
+		{Name: "Submit", Role: "editor", Method: http.MethodPost, Path: transitions, Body: `{"action": "submit"}`, Code: http.StatusOK, Status: models.RevisionInReview},
+		{Name: "Submit twice", Role: "editor", Method: http.MethodPost, Path: transitions, Body: `{"action": "submit"}`, Code: http.StatusConflict},
+		{Name: "Edit in review", Role: "editor", Method: http.MethodPatch, Path: banner, Body: `{"content": {"title": "b"}}`, Code: http.StatusConflict},
+		{Name: "Approve by editor", Role: "editor", Method: http.MethodPost, Path: transitions, Body: `{"action": "approve"}`, Code: http.StatusForbidden},
+		{Name: "Reject", Role: "approver", Method: http.MethodPost, Path: transitions, Body: `{"action": "reject", "comment": "typo"}`, Code: http.StatusOK, Status: models.RevisionDraft},
+		{Name: "Edit draft", Role: "editor", Method: http.MethodPatch, Path: banner, Body: `{"content": {"title": "b"}}`, Code: http.StatusOK},
+		{Name: "Submit again", Role: "editor", Method: http.MethodPost, Path: transitions, Body: `{"action": "submit"}`, Code: http.StatusOK, Status: models.RevisionInReview},
+		{Name: "Withdraw", Role: "editor", Method: http.MethodPost, Path: transitions, Body: `{"action": "withdraw"}`, Code: http.StatusOK, Status: models.RevisionDraft},
+		{Name: "Submit after withdraw", Role: "editor", Method: http.MethodPost, Path: transitions, Body: `{"action": "submit"}`, Code: http.StatusOK, Status: models.RevisionInReview},
+		{Name: "Publish in review", Role: "approver", Method: http.MethodPost, Path: transitions, Body: `{"action": "publish"}`, Code: http.StatusConflict},
+		{Name: "Approve", Role: "approver", Method: http.MethodPost, Path: transitions, Body: `{"action": "approve"}`, Code: http.StatusOK, Status: models.RevisionApproved},
+		{Name: "Edit approved", Role: "editor", Method: http.MethodPatch, Path: banner, Body: `{"content": {"title": "c"}}`, Code: http.StatusConflict},
+		{Name: "Publish by editor", Role: "editor", Method: http.MethodPost, Path: transitions, Body: `{"action": "publish"}`, Code: http.StatusForbidden},
+		{Name: "Publish", Role: "approver", Method: http.MethodPost, Path: transitions, Body: `{"action": "publish"}`, Code: http.StatusOK, Status: models.RevisionPublished},
+		{Name: "Published is visible", Role: "user", Method: http.MethodGet, Path: userBanner, Code: http.StatusOK},
+		{Name: "Publish twice", Role: "approver", Method: http.MethodPost, Path: transitions, Body: `{"action": "publish"}`, Code: http.StatusConflict},
+		{Name: "Edit published", Role: "editor", Method: http.MethodPatch, Path: banner, Body: `{"content": {"title": "d"}}`, Code: http.StatusOK},
+		{Name: "New revision is a draft", Role: "approver", Method: http.MethodPost, Path: transitions, Body: `{"action": "approve"}`, Code: http.StatusConflict},
+	}
+
+	for _, curTest := range testsuite {
+		w := request(curTest.Role, curTest.Method, curTest.Path, curTest.Body)
+		if w.Code != curTest.Code {
+			t.Fatalf("%s: expected status %v; got %v %s", curTest.Name, curTest.Code, w.Code, w.Body.String())
+		}
+		if curTest.Status != "" {
+			var revision models.BannerRevision
+			json.Unmarshal(w.Body.Bytes(), &revision)
+			if revision.Status != curTest.Status {
+				t.Fatalf("%s: expected status %q; got %+v", curTest.Name, curTest.Status, revision)
+			}
+		}
+		t.Log(curTest.Name, ": Pass")
+	}
+
+	// Опубликована ревизия с правкой после отклонения, новая правка идет в следующую ревизию
+	w = request("user", http.MethodGet, userBanner, "")
+	if !strings.Contains(w.Body.String(), `"title":"b"`) {
+		t.Fatalf("Expected published content with title b; got %s", w.Body.String())
+	}
+	w = request("editor", http.MethodGet, banner+"/history", "")
+	var history models.BannerHistory
+	json.Unmarshal(w.Body.Bytes(), &history)
+	// Создание двух ревизий и семь переходов между статусами
+	if len(history.Revisions) != 2 || len(history.Transitions) != 9 {
+		t.Fatalf("Expected 2 revisions and 9 transitions; got %+v", history)
+	}
+	t.Log("History : Pass")
+}